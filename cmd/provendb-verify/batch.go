@@ -0,0 +1,198 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T17:31:44+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T17:31:44+11:00
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+	cli "gopkg.in/urfave/cli.v2"
+)
+
+const batchDateLayout = "2006-01-02"
+
+func handleBatch(c *cli.Context) int {
+	out, err := setup(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	verifier, err := newVerifier(c, out)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	verifier.Anchor.VerifyIndependently = c.Bool("verifyAnchorIndependently")
+
+	opts, err := verifyOptions(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	filter, err := versionFilter(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	cs, err := connString(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	database, err := connect(ctx, cs)
+	if err != nil {
+		return cliErrorf(err.Error())
+	}
+
+	versions, err := verify.FindVerifiableVersions(ctx, database, filter)
+	if err != nil {
+		return cliErrorf("failed to list verifiable versions: %s", err)
+	}
+
+	if len(versions) == 0 {
+		return cliErrorf("no verifiable version is found")
+	}
+
+	if out.format == outputFormatText {
+		fmt.Printf("Verifying %d versions of database `%s`...\n", len(versions), cs.Database)
+
+		// versions are verified at the same time, so only their results are rendered
+		verifier.Events = event.SinkFunc(func(e event.Event) {
+			if e, ok := e.(verify.VersionVerified); ok {
+				fmt.Printf("Version %v is `%s`\n", e.Result.Version.VersionID, e.Result.Status)
+			}
+		})
+	}
+
+	report := verifier.Batch(ctx, database, versions, verify.BatchOptions{
+		Options:     opts,
+		Parallelism: c.Int("parallelism"),
+	})
+
+	code := 0
+	if report.Verified != len(report.Results) {
+		code = 2
+	}
+
+	if out.format == outputFormatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return cliErrorf("failed to output batch verification report: %s", err)
+		}
+
+		fmt.Println(string(data))
+		return code
+	}
+
+	fmt.Printf("\n%-9s\t%-36s\t%-12s\t%s\n", provenDBVersionKey, provenDBProofIDKey, provenDBStatusKey, "reason")
+	for _, r := range report.Results {
+		reason := r.Error
+
+		if r.Report != nil && r.Report.Code != "" {
+			reason = string(r.Report.Code) + ": " + reason
+		}
+
+		fmt.Printf("%-9v\t%-36s\t%-12s\t%s\n", r.Version.VersionID, r.Version.ProofID, r.Status, reason)
+	}
+	fmt.Println()
+
+	summary := fmt.Sprintf("%d versions verified in %s: %d %s, %d %s and %d %s",
+		len(report.Results), report.Duration.Round(time.Millisecond),
+		report.Verified, status.VerificationStatusVerified,
+		report.Falsified, status.VerificationStatusFalsified,
+		report.Unverifiable, status.VerificationStatusUnverifiable)
+
+	if code != 0 {
+		return cliFalsifiedf("%s", summary)
+	}
+
+	return cliVerifiedf("%s", summary)
+}
+
+// versionFilter gets the version filter from the batch flags
+func versionFilter(c *cli.Context) (filter verify.VersionFilter, err error) {
+	filter.MinVersion = int64(c.Int("fromVersion"))
+	filter.MaxVersion = int64(c.Int("toVersion"))
+	filter.Statuses = c.StringSlice("status")
+
+	if filter.MinVersion < 0 || filter.MaxVersion < 0 {
+		err = fmt.Errorf("'--fromVersion' and '--toVersion' must be >= 1")
+		return
+	}
+
+	if filter.MaxVersion != 0 && filter.MinVersion > filter.MaxVersion {
+		err = fmt.Errorf("'--fromVersion' must be <= '--toVersion'")
+		return
+	}
+
+	if v := c.String("since"); v != "" {
+		filter.Since, _, err = parseBatchTime(v)
+		if err != nil {
+			err = fmt.Errorf("invalid '--since': %s", err)
+			return
+		}
+	}
+
+	if v := c.String("until"); v != "" {
+		var isDate bool
+
+		filter.Until, isDate, err = parseBatchTime(v)
+		if err != nil {
+			err = fmt.Errorf("invalid '--until': %s", err)
+			return
+		}
+
+		if isDate {
+			// include the whole day
+			filter.Until = filter.Until.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	if c.Int("parallelism") < 1 {
+		err = fmt.Errorf("'--parallelism' must be >= 1")
+	}
+
+	return
+}
+
+// parseBatchTime parses either a date in local time or a RFC 3339 time
+func parseBatchTime(v string) (t time.Time, isDate bool, err error) {
+	t, err = time.ParseInLocation(batchDateLayout, v, time.Local)
+	if err == nil {
+		isDate = true
+		return
+	}
+
+	t, err = time.Parse(time.RFC3339, v)
+	return
+}
@@ -0,0 +1,46 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:59:31+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:59:31+11:00
+ */
+
+package main
+
+import (
+	"fmt"
+
+	cli "gopkg.in/urfave/cli.v2"
+)
+
+func handleCachePrune(c *cli.Context) int {
+	cache, err := diskCache(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	n, err := cache.Prune(c.Duration("olderThan"))
+	if err != nil {
+		return cliErrorf("failed to prune the anchor evidence cache in `%s`: %s", cache.Dir, err)
+	}
+
+	fmt.Printf("removed %d cached anchor evidence from `%s`\n", n, cache.Dir)
+	return 0
+}
@@ -26,15 +26,19 @@ package main
 
 import (
 	"context"
-	"crypto/rsa"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SouthbankSoftware/provendb-verify/pkg/crypto/rsakey"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
 	"github.com/fatih/color"
 	"github.com/mongodb/mongo-go-driver/mongo"
 	"github.com/mongodb/mongo-go-driver/mongo/options"
@@ -43,42 +47,403 @@ import (
 	cli "gopkg.in/urfave/cli.v2"
 )
 
-type outOpt struct {
-	path string
+// cliOutput prints the CLI output in the chosen format
+type cliOutput struct {
+	format string
 }
 
-type ignoredCollectionsOpt struct {
-	ignoredCollections []string
+// handleCLI handles the flag-only invocations, which are aliases of `verify` except for
+// '--listVersions'
+func handleCLI(c *cli.Context) int {
+	if c.Bool("listVersions") {
+		return handleList(c)
+	}
+
+	return handleVerify(c)
 }
 
-type docOpt struct {
-	colName   string
-	docFilter string
-	calcHash  bool
+func handleVerify(c *cli.Context) int {
+	out, err := setup(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	verifier, err := newVerifier(c, out)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	report := out.report
+
+	if save := c.String("saveEvidence"); save != "" {
+		if ext := filepath.Ext(save); ext != ".json" && ext != ".zip" {
+			return cliErrorf("filename in '--saveEvidence' must end in either '.json' or '.zip'")
+		}
+
+		record := anchor.NewEvidenceBundle()
+		verifier.Anchor.Record = record
+
+		// the evidence is saved once the verification is done. It is only saved when verified, so a
+		// bundle never reproduces a failed verification as if it were the evidence of a proof
+		report = func(r *verify.VerificationReport, err error) int {
+			code := out.report(r, err)
+
+			if err != nil {
+				out.progressf("Not saving anchor evidence to `%s` as the verification has failed\n", save)
+				return code
+			}
+
+			er := record.WriteFile(save)
+			if er != nil {
+				return cliErrorf("failed to save evidence bundle: %s", er)
+			}
+
+			out.progressf("Saved %d anchor evidence to `%s`\n", record.Len(), save)
+			return code
+		}
+	}
+
+	verifier.Anchor.VerifyIndependently = c.Bool("verifyAnchorIndependently")
+
+	cs, err := connString(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	versionID, err := parseVersionID(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	opts, err := verifyOptions(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var proof *model.Proof
+
+	if in := c.String("in"); in != "" {
+		if strings.HasSuffix(in, ".zip") {
+			return report(verifier.Archive(ctx, in, opts))
+		}
+
+		proof, err = verify.LoadProof(in)
+		if err != nil {
+			return cliErrorf(err.Error())
+		}
+
+		out.progressf("Loading Chainpoint Proof `%s`...\n", in)
+	}
+
+	if cs.Database == "" {
+		if proof == nil {
+			return cliErrorf("please specify a database as the verification target")
+		}
+
+		return report(verifier.Proof(ctx, proof, opts))
+	}
+
+	database, err := connect(ctx, cs)
+	if err != nil {
+		return cliErrorf(err.Error())
+	}
+
+	colName, docFilter, err := documentTarget(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	if out := c.String("out"); out != "" {
+		if ext := filepath.Ext(out); ext != ".json" && ext != ".txt" {
+			return cliErrorf("filename in '--out' must end in either '.json' or '.txt'")
+		}
+
+		opts.OutPath = out
+	}
+
+	vp, code := out.versionProof(ctx, c, database, versionID, colName, proof == nil)
+	if code != 0 {
+		return code
+	}
+
+	if proof != nil {
+		// the external Proof takes precedence over the stored one, and the collection scope and
+		// filter of the stored one are kept only when it is loaded by a ProvenDB Proof ID
+		vp.Proof = proof
+	}
+
+	if colName != "" {
+		return report(verifier.Document(ctx, database, vp, colName, docFilter, opts))
+	}
+
+	return report(verifier.Database(ctx, database, vp, opts))
 }
 
-type pubKeyOpt *rsa.PublicKey
+// setup checks the common flags and args, and returns the output in the chosen format
+func setup(c *cli.Context) (*cliOutput, error) {
+	out := &cliOutput{
+		format: outputFormatText,
+	}
 
-func handleCLI(c *cli.Context) int {
-	anchor.VerifyAnchorIndependently = verifyAnchorIndependently
+	if f := c.String("format"); f != "" {
+		out.format = f
+	}
 
-	if c.Bool("help") {
-		cli.ShowAppHelpAndExit(c, 0)
+	if out.format != outputFormatText && out.format != outputFormatJSON {
+		return nil, fmt.Errorf("'--format' must be either '%s' or '%s'", outputFormatText, outputFormatJSON)
 	}
 
 	if c.NArg() > 0 {
-		return cliErrorf("No args should be provided")
+		return nil, errors.New("No args should be provided")
 	}
 
-	if debug {
+	if c.Bool("debug") {
 		log.SetLevel(log.DebugLevel)
 	}
 
-	uri := c.String("uri")
+	return out, nil
+}
 
-	cs, err := connstring.Parse(uri)
+// newVerifier creates a Verifier from the common verification flags
+func newVerifier(c *cli.Context, out *cliOutput) (*verify.Verifier, error) {
+	verifier, err := verify.NewVerifier()
 	if err != nil {
-		return cliErrorf("%s", err)
+		return nil, err
+	}
+
+	verifier.Anchor, err = anchorConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	debug := c.Bool("debug")
+
+	verifier.SkipDocCheck = c.Bool("skipDocCheck")
+	verifier.Debug = debug
+
+	if out.format == outputFormatText {
+		verifier.Events = &progressRenderer{
+			showBar: !debug,
+		}
+	}
+
+	return verifier, nil
+}
+
+// btcAnchorTypes are the Bitcoin anchor types, whose minimum number of confirmations can be
+// overridden by '--btcMinConfirmations'
+var btcAnchorTypes = []string{"btc", "btc_mainnet"}
+
+// anchorConfig creates the anchor verification config from the config file and the anchor flags
+func anchorConfig(c *cli.Context) (*anchor.Config, error) {
+	cfg, err := anchor.NewConfig(c.String("config"))
+	if err != nil {
+		return nil, err
+	}
+
+	setString := func(set func(e *anchor.Endpoint, v string)) func(e *anchor.Endpoint, v string) error {
+		return func(e *anchor.Endpoint, v string) error {
+			set(e, v)
+			return nil
+		}
+	}
+
+	setInt := func(set func(e *anchor.Endpoint, n *int64)) func(e *anchor.Endpoint, v string) error {
+		return func(e *anchor.Endpoint, v string) error {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+
+			set(e, &n)
+			return nil
+		}
+	}
+
+	// the deprecated Bitcoin confirmations are overridden by the ones of each anchor type
+	if c.IsSet("btcMinConfirmations") {
+		for _, anchorType := range btcAnchorTypes {
+			n := int64(c.Int("btcMinConfirmations"))
+			cfg.SetEndpoint(anchorType, anchor.Endpoint{MinConfirmations: &n})
+		}
+	}
+
+	for _, f := range []struct {
+		name string
+		set  func(e *anchor.Endpoint, v string) error
+	}{
+		{"endpoint", setString(func(e *anchor.Endpoint, v string) { e.URL = v })},
+		{"endpointToken", setString(func(e *anchor.Endpoint, v string) { e.Token = v })},
+		{"endpointNetwork", setString(func(e *anchor.Endpoint, v string) { e.Network = v })},
+		{"endpointProvider", setString(func(e *anchor.Endpoint, v string) { e.Provider = v })},
+		{"minConfirmations", setInt(func(e *anchor.Endpoint, n *int64) { e.MinConfirmations = n })},
+		{"quorum", setInt(func(e *anchor.Endpoint, n *int64) { e.Quorum = n })},
+	} {
+		for _, kv := range c.StringSlice(f.name) {
+			anchorType, value, err := splitTypeValue(f.name, kv)
+			if err != nil {
+				return nil, err
+			}
+
+			var e anchor.Endpoint
+
+			err = f.set(&e, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid '--%s': %s", f.name, err)
+			}
+
+			cfg.SetEndpoint(anchorType, e)
+		}
+	}
+
+	// the addresses of the same anchor type are accumulated
+	for _, f := range []struct {
+		name string
+		set  func(e *anchor.Endpoint, addresses []string)
+	}{
+		{"ethSenders", func(e *anchor.Endpoint, addresses []string) { e.Senders = addresses }},
+		{"ethRecipients", func(e *anchor.Endpoint, addresses []string) { e.Recipients = addresses }},
+	} {
+		addresses := make(map[string][]string)
+
+		for _, kv := range c.StringSlice(f.name) {
+			anchorType, value, err := splitTypeValue(f.name, kv)
+			if err != nil {
+				return nil, err
+			}
+
+			addresses[anchorType] = append(addresses[anchorType], value)
+		}
+
+		for anchorType, a := range addresses {
+			var e anchor.Endpoint
+			f.set(&e, a)
+
+			err := e.Validate()
+			if err != nil {
+				return nil, fmt.Errorf("invalid '--%s': %s", f.name, err)
+			}
+
+			cfg.SetEndpoint(anchorType, e)
+		}
+	}
+
+	// the alternates of the same anchor type are accumulated
+	alternates := make(map[string][]anchor.Endpoint)
+
+	for _, kv := range c.StringSlice("alternateEndpoints") {
+		anchorType, value, err := splitTypeValue("alternateEndpoints", kv)
+		if err != nil {
+			return nil, err
+		}
+
+		alternates[anchorType] = append(alternates[anchorType], alternateEndpoint(value))
+	}
+
+	for anchorType, a := range alternates {
+		cfg.SetEndpoint(anchorType, anchor.Endpoint{Alternates: a})
+	}
+
+	cfg.BtcAnchorBranchType = c.String("btcAnchorBranchType")
+
+	if h := c.String("btcHeaders"); h != "" {
+		cfg.BtcHeaders, err = anchor.LoadHeaderChain(h, int64(c.Int("btcHeadersStart")))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.Offline = c.Bool("offline")
+
+	if e := c.String("evidence"); e != "" {
+		cfg.Evidence, err = anchor.LoadEvidenceBundle(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !c.Bool("noCache") {
+		cfg.Cache, err = diskCache(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// diskCache creates the anchor evidence cache in the directory given by '--cacheDir'
+func diskCache(c *cli.Context) (*anchor.DiskCache, error) {
+	dir := c.String("cacheDir")
+
+	if dir == "" {
+		var err error
+
+		dir, err = anchor.DefaultCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot find the default '--cacheDir': %s", err)
+		}
+	}
+
+	return anchor.NewDiskCache(dir), nil
+}
+
+// alternateEndpoint parses an alternate endpoint in the form of PROVIDER, URL or PROVIDER@URL
+func alternateEndpoint(value string) anchor.Endpoint {
+	i := strings.Index(value, "://")
+	if i < 0 {
+		return anchor.Endpoint{Provider: value}
+	}
+
+	if j := strings.Index(value[:i], "@"); j > 0 {
+		return anchor.Endpoint{Provider: value[:j], URL: value[j+1:]}
+	}
+
+	return anchor.Endpoint{URL: value}
+}
+
+// splitTypeValue splits the given value of the flag in the form of TYPE=VALUE
+func splitTypeValue(flag, kv string) (anchorType, value string, err error) {
+	i := strings.Index(kv, "=")
+	if i <= 0 || i == len(kv)-1 {
+		err = fmt.Errorf("invalid '--%s': `%s` is not in the form of TYPE=VALUE", flag, kv)
+		return
+	}
+
+	return kv[:i], kv[i+1:], nil
+}
+
+// verifyOptions gets the verification options from the common verification flags
+func verifyOptions(c *cli.Context) (opts verify.Options, err error) {
+	opts.IgnoredCollections = c.StringSlice("ignoredCollections")
+
+	if v := c.String("pubKey"); v != "" {
+		pubPEM, er := ioutil.ReadFile(v)
+		if er != nil {
+			err = fmt.Errorf("invalid '--pubKey': %s", er)
+			return
+		}
+
+		pub, er := rsakey.ImportPublicKeyFromPEM(pubPEM)
+		if er != nil {
+			err = fmt.Errorf("invalid '--pubKey': %s", er)
+			return
+		}
+
+		opts.PubKey = pub
+	}
+
+	return
+}
+
+// connString gets the MongoDB connection string from the connection flags
+func connString(c *cli.Context) (cs connstring.ConnString, err error) {
+	cs, err = connstring.Parse(c.String("uri"))
+	if err != nil {
+		return
 	}
 
 	if !cs.MaxPoolSizeSet {
@@ -91,7 +456,8 @@ func handleCLI(c *cli.Context) int {
 
 	if host != "" || port != "" {
 		if len(cs.Hosts) != 1 {
-			return cliErrorf("'--host' or '--port' cannot be used to override multiple hosts in URI")
+			err = errors.New("'--host' or '--port' cannot be used to override multiple hosts in URI")
+			return
 		}
 
 		addr := strings.Split(cs.Hosts[0], ":")
@@ -107,12 +473,14 @@ func handleCLI(c *cli.Context) int {
 		}
 
 		if port != "" {
-			d, err := strconv.Atoi(port)
-			if err != nil {
-				return cliErrorf("port must be an integer: %s", err)
+			d, er := strconv.Atoi(port)
+			if er != nil {
+				err = fmt.Errorf("port must be an integer: %s", er)
+				return
 			}
 			if d <= 0 || d >= 65536 {
-				return cliErrorf("port must be in the range [1, 65535]")
+				err = errors.New("port must be in the range [1, 65535]")
+				return
 			}
 			p = strconv.Itoa(d)
 		}
@@ -141,171 +509,174 @@ func handleCLI(c *cli.Context) int {
 		cs.Database = d
 	}
 
-	if c.IsSet(provenDBVersionIDKey) && c.IsSet(provenDBProofIDKey) {
-		return cliErrorf("'--%s' and '--%s' cannot be both set", provenDBVersionIDKey, provenDBProofIDKey)
+	return
+}
+
+// connect connects to the database in the given connection string
+func connect(ctx context.Context, cs connstring.ConnString) (*mongo.Database, error) {
+	if cs.Database == "" {
+		return nil, errors.New("please specify a database as the verification target")
 	}
 
-	var versionID int64 = -1
+	cOpts := options.Client()
+	cOpts.ConnString = cs
 
-	if v := c.String(provenDBVersionIDKey); v != provenDBVersionCurrent {
-		vNum, err := strconv.Atoi(v)
-		if err != nil {
-			return cliErrorf("invalid '--%s': %s", provenDBVersionIDKey, err)
-		}
+	client, err := mongo.NewClientWithOptions("mongodb://localhost", cOpts)
+	if err != nil {
+		return nil, err
+	}
 
-		if vNum < 1 {
-			return cliErrorf("invalid '--%s': version must be >= 1", provenDBVersionIDKey)
-		}
+	err = client.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		versionID = int64(vNum)
+	return client.Database(cs.Database), nil
+}
+
+// parseVersionID gets the version from '--versionId'. It returns -1 for the current version
+func parseVersionID(c *cli.Context) (int64, error) {
+	if c.IsSet(provenDBVersionIDKey) && c.IsSet(provenDBProofIDKey) {
+		return 0, fmt.Errorf("'--%s' and '--%s' cannot be both set", provenDBVersionIDKey, provenDBProofIDKey)
 	}
 
-	var pubKeyOpt pubKeyOpt
+	v := c.String(provenDBVersionIDKey)
+	if v == provenDBVersionCurrent {
+		return -1, nil
+	}
 
-	if v := c.String("pubKey"); v != "" {
-		pubPEM, err := ioutil.ReadFile(v)
-		if err != nil {
-			return cliErrorf("invalid '--pubKey': %s", err)
-		}
+	vNum, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid '--%s': %s", provenDBVersionIDKey, err)
+	}
 
-		pub, err := rsakey.ImportPublicKeyFromPEM(pubPEM)
-		if err != nil {
-			return cliErrorf("invalid '--pubKey': %s", err)
-		}
+	if vNum < 1 {
+		return 0, fmt.Errorf("invalid '--%s': version must be >= 1", provenDBVersionIDKey)
+	}
 
-		pubKeyOpt = pub
+	return int64(vNum), nil
+}
+
+// documentTarget gets the collection name and document filter of the target document, which are
+// both empty when the target is the whole database
+func documentTarget(c *cli.Context) (colName, docFilter string, err error) {
+	colName = c.String("collection")
+	docFilter = c.String("docFilter")
+
+	if (colName != "") != (docFilter != "") {
+		err = errors.New("'--collection' and '--docFilter' must be both specified or left out")
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	return
+}
 
-	var proof interface{}
+// versionProof resolves the version to be verified, which is given by '--versionId' or
+// '--proofId', or is the latest verifiable one. The stored Chainpoint Proof of the version is
+// loaded when `loadProof` is true or the version is given by '--proofId'. It returns a non-zero
+// exit code when failed
+func (o *cliOutput) versionProof(ctx context.Context, c *cli.Context, database *mongo.Database,
+	versionID int64, colName string, loadProof bool) (vp verify.VersionProof, code int) {
+	vp.Version = versionID
+
+	if versionID == -1 {
+		if p := c.String(provenDBProofIDKey); p != "" {
+			// use proofId to get versionId
+			storedVP, err := verify.GetProof(ctx, database, p, colName)
+			if err != nil {
+				code = cliErrorf("cannot get Chainpoint Proof using %s %s: %s", provenDBProofIDKey, p, err)
+				return
+			}
 
-	if in := c.String("in"); in != "" {
-		if strings.HasSuffix(in, ".zip") {
-			msg, err := verifyProofArchive(ctx, in, pubKeyOpt)
+			o.storedProof(storedVP)
+			vp = storedVP
+		} else {
+			v, err := verify.GetLatestVerifiableVersion(ctx, database)
 			if err != nil {
-				return cliFalsifiedf("%s:\n\t%s", msg, err)
+				code = cliErrorf("failed to get the latest verifiable version: %s", err)
+				return
 			}
 
-			return cliVerifiedf("%s", msg)
+			vp.Version = v
 		}
+	}
 
-		proof, err = loadProof(in)
+	if vp.Proof == nil && loadProof {
+		storedVP, err := verify.GetProof(ctx, database, vp.Version, colName)
 		if err != nil {
-			return cliErrorf(err.Error())
+			code = cliErrorf("cannot get Chainpoint Proof using %s %v: %s", provenDBVersionKey, vp.Version, err)
+			return
 		}
 
-		fmt.Printf("Loading Chainpoint Proof `%s`...\n", in)
+		o.storedProof(storedVP)
+		vp = storedVP
 	}
 
-	var (
-		cols      []string
-		database  *mongo.Database
-		filterStr string
-		opts      []interface{}
-	)
-
-	if cs.Database == "" {
-		if proof == nil {
-			return cliErrorf("please specify a database as the verification target")
-		}
-	} else {
-		cOpts := options.Client()
-		cOpts.ConnString = cs
+	return
+}
 
-		client, err := mongo.NewClientWithOptions("mongodb://localhost", cOpts)
-		if err != nil {
-			return cliErrorf(err.Error())
-		}
+func (o *cliOutput) storedProof(vp verify.VersionProof) {
+	statusMsg := ""
 
-		err = client.Connect(ctx)
-		if err != nil {
-			return cliErrorf(err.Error())
-		}
+	if vp.Status == "invalid" {
+		statusMsg = ", which is in `invalid` status"
+	}
 
-		database = client.Database(cs.Database)
+	o.progressf("Loading Chainpoint Proof `%s`%s...\n", vp.ProofID, statusMsg)
 
-		if c.Bool("listVersions") {
-			versions, err := getVerifiableVersions(ctx, database)
-			if err != nil {
-				return cliErrorf("failed to list verifiable versions: %s", err)
-			}
+	if vp.Filter != "" {
+		o.progressf("Using proof filter '%s'\n", vp.Filter)
+	}
+}
 
-			fmt.Printf("%-36s\t%-9s\t%-30v\t%s\n", provenDBProofIDKey, provenDBVersionKey, provenDBSubmittedKey, provenDBStatusKey)
-			for _, v := range versions {
-				fmt.Printf("%-36s\t%-9v\t%-30v\t%s\n", v.proofID, v.versionID, v.submitTimestamp, v.proofStatus)
-			}
-			return 0
+// report outputs the verification report in the chosen format and returns the exit code
+func (o *cliOutput) report(report *verify.VerificationReport, err error) int {
+	if o.format == outputFormatJSON {
+		data, er := json.MarshalIndent(report, "", "  ")
+		if er != nil {
+			return cliErrorf("failed to output verification report: %s", er)
 		}
 
-		colName := c.String("collection")
+		fmt.Println(string(data))
 
-		if docFilter := c.String("docFilter"); colName != "" && docFilter != "" {
-			opts = append(opts, docOpt{
-				colName,
-				docFilter,
-				false,
-			})
-		} else if colName != "" || docFilter != "" {
-			return cliErrorf("'--collection' and '--docFilter' must be both specified or left out")
+		if err != nil {
+			return 2
 		}
 
-		if out := c.String("out"); out != "" {
-			if ext := filepath.Ext(out); ext != ".json" && ext != ".txt" {
-				return cliErrorf("filename in '--out' must end in either '.json' or '.txt'")
+		return 0
+	}
+
+	if err != nil {
+		if report.Code != "" {
+			reason := string(report.Code)
+
+			if report.Location != nil {
+				reason += " at " + report.Location.String()
 			}
 
-			opts = append(opts, outOpt{
-				out,
-			})
+			return cliFalsifiedf("%s (%s):\n\t%s", report.Message, reason, err)
 		}
 
-		if ignoredCollections := c.StringSlice("ignoredCollections"); ignoredCollections != nil {
+		return cliFalsifiedf("%s:\n\t%s", report.Message, err)
+	}
 
-			opts = append(opts, ignoredCollectionsOpt{
-				ignoredCollections,
-			})
-		}
+	code := cliVerifiedf("%s", report.Message)
 
-		if versionID == -1 {
-			if p := c.String(provenDBProofIDKey); p != "" {
-				// use proofId to get versionId
-				var storedProof interface{}
-				storedProof, versionID, cols, filterStr, err = getProof(ctx, database, p, colName)
-				if err != nil {
-					return cliErrorf("cannot get Chainpoint Proof using %s %s: %s", provenDBProofIDKey, p, err)
-				}
-
-				if proof == nil {
-					proof = storedProof
-				}
-			} else {
-				versionID, err = getLatestVerifiableVersion(ctx, database)
-				if err != nil {
-					return cliErrorf("failed to get the latest verifiable version: %s", err)
-				}
-			}
-		}
+	if report.AnchoredAt != nil {
+		fmt.Printf("Data existed no later than %s, when it was anchored\n", report.AnchoredAt.Format(time.RFC3339))
 
-		if proof == nil {
-			proof, _, cols, filterStr, err = getProof(ctx, database, versionID, colName)
-			if err != nil {
-				return cliErrorf("cannot get Chainpoint Proof using %s %v: %s", provenDBVersionKey, versionID, err)
-			}
+		if report.HashSubmittedCoreAt != "" {
+			fmt.Printf("The Chainpoint Proof claims the hash was submitted at %s\n", report.HashSubmittedCoreAt)
 		}
 	}
 
-	if pubKeyOpt != nil {
-		opts = append(opts, pubKeyOpt)
-	}
+	return code
+}
 
-	msg, err := verifyProof(ctx, database, proof, versionID, cols, filterStr, opts...)
-	if err != nil {
-		return cliFalsifiedf("%s:\n\t%s", msg, err)
+// progressf prints progress messages, which are only shown in the text output format
+func (o *cliOutput) progressf(format string, a ...interface{}) {
+	if o.format == outputFormatText {
+		fmt.Printf(format, a...)
 	}
-
-	return cliVerifiedf("%s", msg)
 }
 
 func cliVerifiedf(format string, a ...interface{}) int {
@@ -0,0 +1,81 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T16:40:18+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T16:40:18+11:00
+ */
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+	cli "gopkg.in/urfave/cli.v2"
+)
+
+func handleExport(c *cli.Context) int {
+	out, err := setup(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	outPath := c.String("out")
+
+	if outPath == "" {
+		return cliErrorf("please specify a path in '--out' to export the Chainpoint Proof")
+	}
+
+	if ext := filepath.Ext(outPath); ext != ".json" && ext != ".txt" {
+		return cliErrorf("filename in '--out' must end in either '.json' or '.txt'")
+	}
+
+	cs, err := connString(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	versionID, err := parseVersionID(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	database, err := connect(ctx, cs)
+	if err != nil {
+		return cliErrorf(err.Error())
+	}
+
+	vp, code := out.versionProof(ctx, c, database, versionID, c.String("collection"), true)
+	if code != 0 {
+		return code
+	}
+
+	out.progressf("Outputting Chainpoint Proof to `%s`...\n", outPath)
+
+	err = verify.SaveProof(outPath, vp.Proof)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	return 0
+}
@@ -17,464 +17,78 @@
  *
  *
  * @Author: guiguan
- * @Date:   2019-04-02T13:42:23+11:00
- * @Last modified by:   Michael Harrison
- * @Last modified time: 2020-01-15T09:41:19+11:00
+ * @Date:   2026-10-16T16:40:18+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T16:40:18+11:00
  */
-
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/hex"
 	"fmt"
-	"sort"
 
-	hasher "github.com/SouthbankSoftware/provendb-verify/pkg/crypto/sha256"
-	"github.com/SouthbankSoftware/provendb-verify/pkg/merkle"
-	"github.com/SouthbankSoftware/provendb-verify/pkg/merkle/chainpoint"
-	"github.com/mongodb/mongo-go-driver/bson"
-	"github.com/mongodb/mongo-go-driver/mongo"
-	"github.com/mongodb/mongo-go-driver/mongo/options"
-	"github.com/mongodb/mongo-go-driver/x/bsonx"
-	log "github.com/sirupsen/logrus"
-	"github.com/vbauerster/mpb"
-	"github.com/vbauerster/mpb/decor"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+	cli "gopkg.in/urfave/cli.v2"
 )
 
-type hashResult struct {
-	name   string
-	hash   []byte
-	height int
-	size   int
-	proofs []merkle.Proof
-}
-
-func hashDatabase(
-	ctx context.Context,
-	database *mongo.Database,
-	version int64,
-	proofMap map[string]map[string]*merkle.Proof,
-	cols []string,
-	ignoredCollections []string,
-	filterStr string,
-) (result hashResult, err error) {
-	select {
-	case <-ctx.Done():
-		return result, ctx.Err()
-	default:
-	}
-
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	ignoredCollectionsRegex := ""
-	for _, collection := range ignoredCollections {
-		ignoredCollectionsRegex += "^" + collection + "$|"
-	}
-
-	colNameFilter := bsonx.Doc{
-		{"$not", bsonx.Regex(
-			"^"+provenDBMetaPrefix+"|^"+mongoDBSystemPrefix+"|"+ignoredCollectionsRegex+provenDBIgnoredSuffix+"$",
-			"",
-		)},
-	}
-
-	if len(cols) > 0 {
-		vals := bsonx.Arr{}
-
-		for _, colName := range cols {
-			vals = append(vals, bsonx.String(colName))
-		}
-
-		colNameFilter = append(colNameFilter, bsonx.Elem{"$in", bsonx.Array(vals)})
-	}
-
-	// list normal collections
-	cur, err := database.ListCollections(ctx,
-		bsonx.Doc{
-			{"name", bsonx.Document(colNameFilter)},
-			{"type", bsonx.String("collection")},
-		},
-		options.ListCollections().SetNameOnly(true),
-	)
+func handleHash(c *cli.Context) int {
+	out, err := setup(c)
 	if err != nil {
-		return result, err
-	}
-	defer cur.Close(ctx)
-
-	colHashesCH := make(chan hashResult, 10)
-	errCH := make(chan error)
-	count := 0
-
-	asyncHashCol := func(collection *mongo.Collection, proofKeys ...[]byte) {
-		result, err := hashCollection(ctx, collection, version, filterStr, proofKeys...)
-		if err != nil {
-			select {
-			case <-ctx.Done():
-			case errCH <- err:
-			}
-
-			return
-		}
-
-		select {
-		case <-ctx.Done():
-		case colHashesCH <- result:
-		}
-	}
-
-	for cur.Next(ctx) {
-		doc := bsonx.Doc{}
-		err := cur.Decode(&doc)
-		if err != nil {
-			return result, err
-		}
-
-		colName, ok := doc.Lookup("name").StringValueOK()
-		if !ok {
-			return result, fmt.Errorf("cannot get name when list collections")
-		}
-
-		var proofKeys [][]byte
-
-		if docProofMap, ok := proofMap[colName]; ok {
-			for _, v := range docProofMap {
-				proofKeys = append(proofKeys, v.Key)
-			}
-		}
-
-		go asyncHashCol(database.Collection(colName), proofKeys...)
-
-		count++
-	}
-
-	var (
-		entries      = make(merkle.BagEntries, 0, count)
-		i            = 0
-		height, size int
-		progress     *mpb.Progress
-		bar          *mpb.Bar
-	)
-
-	if !debug {
-		progress = mpb.New(
-			mpb.WithWidth(64),
-			mpb.WithFormat(" \u2588\u2588\u2591 "),
-			// canceled when context is canceled
-			mpb.WithContext(ctx),
-		)
-
-		name := fmt.Sprintf("Hashing database `%s`...", database.Name())
-		bar = progress.AddBar(int64(count+1),
-			mpb.PrependDecorators(
-				decor.Name(name, decor.WC{W: len(name) + 1, C: decor.DidentRight}),
-				decor.CountersNoUnit("%d/%d"),
-			),
-			mpb.AppendDecorators(
-				decor.OnComplete(decor.Percentage(), ""),
-			),
-			mpb.BarClearOnComplete(),
-		)
-
-		defer func() {
-			// wait for progress bar to finish rendering
-			progress.Wait()
-		}()
-	}
-
-	for i < count {
-		select {
-		case <-ctx.Done():
-			return result, ctx.Err()
-		case err := <-errCH:
-			if !debug {
-				// cancel progress bar render waiting
-				progress.Abort(bar, false)
-			}
-
-			return result, err
-		case r := <-colHashesCH:
-			if r.hash == nil {
-				// empty collection
-				count--
-
-				if !debug {
-					bar.SetTotal(int64(count + 1), false)
-				}
-
-				// skip
-				break
-			}
-
-			entries = append(entries, merkle.BagEntry{
-				[]byte(r.name),
-				r.hash,
-			})
-
-			if r.height > height {
-				height = r.height
-			}
-
-			size += r.size
-
-			if r.proofs != nil {
-				if docProofMap, ok := proofMap[r.name]; ok {
-					for _, p := range r.proofs {
-						if proof, ok := docProofMap[string(p.Key)]; ok {
-							proof.Value = p.Value
-							proof.ValueHashAlgorithm = p.ValueHashAlgorithm
-							proof.HashCombiningAlgorithm = p.HashCombiningAlgorithm
-							proof.Path = p.Path
-						}
-					}
-				}
-			}
-
-			if !debug {
-				bar.IncrBy(1)
-			}
-
-			i++
-		}
-	}
-
-	bagHasher := chainpoint.NewBagHasher()
-	var colProofKeys [][]byte
-
-	for k := range proofMap {
-		colProofKeys = append(colProofKeys, []byte(k))
-	}
-
-	sort.Sort(entries)
-
-	if debug {
-		log.Debug("Hashes of collections will be assembled in this order:")
-
-		for _, b := range entries {
-			log.Debugf("\t%s", b[0])
-		}
-	}
-
-	hash, proofs := bagHasher.Patch(entries, colProofKeys...)
-
-	if !debug {
-		bar.IncrBy(1)
-	}
-
-	if hash == nil {
-		// empty database version
-		return hashResult{
-			hash: hasher.EmptyString,
-		}, nil
-	}
-
-	var finalProofs []merkle.Proof
-
-	// merge collection proofs with document proofs to form the complete proof for documents
-	for _, p := range proofs {
-		if docProofMap, ok := proofMap[string(p.Key)]; ok {
-			for _, proof := range docProofMap {
-				if len(proof.Value) != 0 {
-					// merge for non-empty document proof
-					proof.RootHash = hash
-					proof.Path = append(proof.Path, p.Path...)
-				}
-			}
-		}
-	}
-
-	for colName, docProofMap := range proofMap {
-		for _, proof := range docProofMap {
-			proof.Meta = colName
-			finalProofs = append(finalProofs, *proof)
-		}
-	}
-
-	return hashResult{
-		database.Name(),
-		hash,
-		height + bagHasher.Height(),
-		size - count + bagHasher.Size(),
-		finalProofs,
-	}, nil
-}
-
-func hashDocument(doc bsonx.Doc) (hash []byte, metaDoc bsonx.Doc, err error) {
-	docID := doc.Lookup(idKey)
-	idEl := bsonx.Elem{}
-
-	defer func() {
-		if err != nil {
-			var pIDStr string
-
-			if !idEl.Equal(bsonx.Elem{}) {
-				pIDStr = fmt.Sprintf(" (ProvenDB ID `%v`)", idEl.Value.Interface())
-			}
-
-			err = fmt.Errorf("document `%v`%s: %s", docID, pIDStr, err)
-		}
-	}()
-
-	metaDoc, ok := doc.Lookup(provenDBDocMetaKey).DocumentOK()
-	if !ok {
-		err = fmt.Errorf("cannot get ProvenDB metadata")
-		return
+		return cliErrorf("%s", err)
 	}
 
-	doc = doc.Delete(idKey)
+	// the hash is the only output, so progress messages are not printed
+	out.format = outputFormatJSON
 
-	idEl, err = metaDoc.LookupElementErr(idKey)
+	verifier, err := newVerifier(c, out)
 	if err != nil {
-		return
+		return cliErrorf("%s", err)
 	}
 
-	minVerEl, err := metaDoc.LookupElementErr(provenDBMinVersionKey)
+	cs, err := connString(c)
 	if err != nil {
-		return
+		return cliErrorf("%s", err)
 	}
 
-	getExpectedHash := func() (hash []byte, err error) {
-		if el, er := metaDoc.LookupElementErr(provenDBHashKey); er == nil {
-			if val := el.Value.Interface(); val != nil {
-				if str, ok := val.(string); ok {
-					if h, er := hex.DecodeString(str); er == nil {
-						hash = h
-					} else {
-						err = er
-						return
-					}
-				} else {
-					err = fmt.Errorf("cannot convert %s element value to string", provenDBHashKey)
-					return
-				}
-			}
-		} else {
-			err = er
-			return
-		}
-
-		return
-	}
-
-	forgottenVl := metaDoc.Lookup(provenDBForgottenKey)
-	if f, ok := forgottenVl.BooleanOK(); ok && f {
-		hash, err = getExpectedHash()
-		return
-	}
-
-	doc.Set(provenDBDocMetaKey, bsonx.Document(bsonx.Doc{
-		idEl,
-		minVerEl,
-	}))
-
-	docBA, err := doc.MarshalBSON()
+	versionID, err := parseVersionID(c)
 	if err != nil {
-		return
+		return cliErrorf("%s", err)
 	}
 
-	hash = hasher.HashByteArray(docBA)
-
-	if !skipDocCheck {
-		// check document hash against metadata
-		expectedHash, er := getExpectedHash()
-		if er != nil {
-			err = er
-			return
-		}
-
-		if expectedHash != nil && bytes.Compare(hash, expectedHash) != 0 {
-			j, _ := bson.MarshalExtJSON(doc, true, false)
-			err = fmt.Errorf("document hash mismatched. Expected: %x, actual: %x. Hashed document content: %s", expectedHash, hash, j)
-			return
-		}
-	}
-
-	return
-}
-
-func hashCollection(ctx context.Context, collection *mongo.Collection, version int64, filterStr string, proofKeys ...[]byte) (result hashResult, err error) {
-	select {
-	case <-ctx.Done():
-		return result, ctx.Err()
-	default:
+	colName, docFilter, err := documentTarget(c)
+	if err != nil {
+		return cliErrorf("%s", err)
 	}
 
-	defer func() {
-		if err != nil {
-			err = fmt.Errorf("collection `%s`: %s", collection.Name(), err)
-		}
-	}()
-
-	// If there is a filter, unmarshal it.
-	var filter bsonx.Doc
-	if filterStr != "" {
-		err = bson.UnmarshalExtJSON([]byte(filterStr), true, &filter)
-		if err != nil {
-			return result, fmt.Errorf("invalid `filter`: %s", err.Error())
-		}
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	cur, err := findDocs(ctx, collection, version, filter)
+	database, err := connect(ctx, cs)
 	if err != nil {
-		return result, err
+		return cliErrorf(err.Error())
 	}
-	defer cur.Close(ctx)
-
-	var (
-		entries = make(merkle.BagEntries, 0, 10)
-		i       = 0
-	)
 
-	for cur.Next(ctx) {
-		doc := bsonx.Doc{}
-		err := cur.Decode(&doc)
+	if versionID == -1 {
+		versionID, err = verify.GetLatestVerifiableVersion(ctx, database)
 		if err != nil {
-			return result, err
-		}
-
-		hash, metaDoc, err := hashDocument(doc)
-		if err != nil {
-			return result, err
+			return cliErrorf("failed to get the latest verifiable version: %s", err)
 		}
+	}
 
-		key, err := getHashKeyFromDoc(metaDoc)
-		if err != nil {
-			return result, err
-		}
+	var hash []byte
 
-		entries = append(entries, merkle.BagEntry{
-			key,
-			hash,
+	if colName != "" {
+		hash, err = verifier.HashDocument(ctx, database, versionID, colName, docFilter)
+	} else {
+		hash, err = verifier.HashDatabase(ctx, database, versionID, verify.Options{
+			IgnoredCollections: c.StringSlice("ignoredCollections"),
 		})
-		i++
-	}
-
-	if i == 0 {
-		// return empty string hash for empty collection
-		return hashResult{
-			collection.Name(),
-			nil,
-			0,
-			0,
-			nil,
-		}, nil
 	}
-
-	bagHasher := chainpoint.NewBagHasher()
-
-	hash, proofs := bagHasher.Patch(entries, proofKeys...)
-
-	if debug {
-		log.Debugf("Finished hashing collection `%s`: %x", collection.Name(), hash)
+	if err != nil {
+		return cliErrorf("failed to hash version %v: %s", versionID, err)
 	}
 
-	return hashResult{
-		collection.Name(),
-		hash,
-		bagHasher.Height(),
-		bagHasher.Size(),
-		proofs,
-	}, nil
+	fmt.Println(hex.EncodeToString(hash))
+	return 0
 }
@@ -0,0 +1,123 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T16:40:18+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T16:40:18+11:00
+ */
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/eval"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+	cli "gopkg.in/urfave/cli.v2"
+)
+
+// handleInspect evaluates a Chainpoint Proof and renders its branch tree. It makes no network
+// calls, so a Proof can be understood before trusting any verification result
+func handleInspect(c *cli.Context) int {
+	out, err := setup(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	in := c.String("in")
+
+	if in == "" {
+		return cliErrorf("please specify a Chainpoint Proof in '--in' to inspect")
+	}
+
+	proof, err := verify.LoadProof(in)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	evaluatedProof, err := eval.Eval(proof)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	if out.format == outputFormatJSON {
+		data, err := json.MarshalIndent(evaluatedProof, "", "  ")
+		if err != nil {
+			return cliErrorf("failed to output evaluated Chainpoint Proof: %s", err)
+		}
+
+		fmt.Println(string(data))
+		return 0
+	}
+
+	renderEvaluatedProof(os.Stdout, in, evaluatedProof)
+	return 0
+}
+
+// renderEvaluatedProof renders the evaluated Proof as a branch tree
+func renderEvaluatedProof(w io.Writer, name string, p *model.EvaluatedProof) {
+	fmt.Fprintf(w, "Chainpoint Proof `%s`\n", name)
+	fmt.Fprintf(w, "  hash:                   %s\n", p.Hash)
+	fmt.Fprintf(w, "  hash_id_node:           %s\n", p.HashIDNode)
+	fmt.Fprintf(w, "  hash_submitted_node_at: %s\n", p.HashSubmittedNodeAt)
+	fmt.Fprintf(w, "  hash_id_core:           %s\n", p.HashIDCore)
+	fmt.Fprintf(w, "  hash_submitted_core_at: %s\n", p.HashSubmittedCoreAt)
+
+	renderEvaluatedBranches(w, p.Branches, 1)
+}
+
+func renderEvaluatedBranches(w io.Writer, branches []model.EvaluatedBranch, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	for _, b := range branches {
+		label := b.Label
+		if label == "" {
+			label = "(unlabeled)"
+		}
+
+		fmt.Fprintf(w, "%s- branch `%s` (%d ops)\n", indent, label, b.OpCount)
+		fmt.Fprintf(w, "%s    start hash: %s\n", indent, b.StartHash)
+		fmt.Fprintf(w, "%s    end hash:   %s\n", indent, b.EndHash)
+
+		if b.Sig != "" {
+			fmt.Fprintf(w, "%s    signature at op %d: %s\n", indent, b.SigOp, b.Sig)
+			fmt.Fprintf(w, "%s      signed hash: %s\n", indent, b.SigHash)
+		}
+
+		if b.BtcTxID != "" {
+			fmt.Fprintf(w, "%s    Bitcoin transaction: %s\n", indent, b.BtcTxID)
+			fmt.Fprintf(w, "%s      OP_RETURN: %s\n", indent, b.OpReturnValue)
+		}
+
+		for _, a := range b.Anchors {
+			fmt.Fprintf(w, "%s    anchor `%s` %s at op %d\n", indent, a.Type, a.AnchorID, a.Op)
+			fmt.Fprintf(w, "%s      expected value: %s\n", indent, a.ExpectedValue)
+
+			for _, uri := range a.URIs {
+				fmt.Fprintf(w, "%s      uri: %s\n", indent, uri)
+			}
+		}
+
+		renderEvaluatedBranches(w, b.Branches, depth+1)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T16:40:18+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T16:40:18+11:00
+ */
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+	cli "gopkg.in/urfave/cli.v2"
+)
+
+func handleList(c *cli.Context) int {
+	out, err := setup(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	cs, err := connString(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	database, err := connect(ctx, cs)
+	if err != nil {
+		return cliErrorf(err.Error())
+	}
+
+	versions, err := verify.GetVerifiableVersions(ctx, database)
+	if err != nil {
+		return cliErrorf("failed to list verifiable versions: %s", err)
+	}
+
+	if out.format == outputFormatJSON {
+		if versions == nil {
+			versions = []verify.VerifiableVersion{}
+		}
+
+		data, err := json.MarshalIndent(versions, "", "  ")
+		if err != nil {
+			return cliErrorf("failed to output verifiable versions: %s", err)
+		}
+
+		fmt.Println(string(data))
+		return 0
+	}
+
+	fmt.Printf("%-36s\t%-9s\t%-30v\t%s\n", provenDBProofIDKey, provenDBVersionKey, provenDBSubmittedKey, provenDBStatusKey)
+	for _, v := range versions {
+		fmt.Printf("%-36s\t%-9v\t%-30v\t%s\n", v.ProofID, v.VersionID, v.SubmitTimestamp, v.ProofStatus)
+	}
+
+	return 0
+}
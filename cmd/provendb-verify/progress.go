@@ -0,0 +1,149 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T14:05:12+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T14:05:12+11:00
+ */
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+	"github.com/vbauerster/mpb"
+	"github.com/vbauerster/mpb/decor"
+)
+
+var anchorCheckNames = map[string]string{
+	anchor.CheckURI:                "anchor URI",
+	anchor.CheckBtcTxOpReturn:      "Bitcoin transaction OP_RETURN",
+	anchor.CheckBtcBlockMerkleRoot: "Bitcoin block merkle root",
+	anchor.CheckEthTxData:          "Ethereum transaction data",
+	anchor.CheckHederaTxMemo:       "Hedera transaction data",
+}
+
+// progressRenderer renders verification events as progress messages and a database hashing
+// progress bar
+type progressRenderer struct {
+	// showBar indicates whether to render the progress bar, which is disabled when debug logs are
+	// printed
+	showBar bool
+
+	mu       sync.Mutex
+	progress *mpb.Progress
+	bar      *mpb.Bar
+	total    int
+}
+
+var _ event.Sink = (*progressRenderer)(nil)
+
+func (p *progressRenderer) Handle(e event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch e := e.(type) {
+	case verify.StageStarted:
+		switch e.Stage {
+		case verify.StageLoadArchive:
+			fmt.Printf("Loading ProvenDB Proof Archive `%s`...\n", e.Detail)
+		case verify.StageVerifyProof:
+			fmt.Println("Verifying Chainpoint Proof...")
+		case verify.StageVerifySignatures:
+			fmt.Println("Verifying Chainpoint Proof signature...")
+		case verify.StageOutputProof:
+			fmt.Printf("Outputting Chainpoint Proof to `%s`...\n", e.Detail)
+		}
+	case verify.DatabaseHashStarted:
+		if !p.showBar {
+			return
+		}
+
+		p.total = e.Collections + 1
+		p.progress = mpb.New(
+			mpb.WithWidth(64),
+			mpb.WithFormat(" \u2588\u2588\u2591 "),
+		)
+
+		name := fmt.Sprintf("Hashing database `%s`...", e.Database)
+		p.bar = p.progress.AddBar(int64(p.total),
+			mpb.PrependDecorators(
+				decor.Name(name, decor.WC{W: len(name) + 1, C: decor.DidentRight}),
+				decor.CountersNoUnit("%d/%d"),
+			),
+			mpb.AppendDecorators(
+				decor.OnComplete(decor.Percentage(), ""),
+			),
+			mpb.BarClearOnComplete(),
+		)
+	case verify.CollectionHashed:
+		if p.bar == nil {
+			return
+		}
+
+		if e.Hash == nil {
+			// empty collection is skipped
+			p.total--
+			p.bar.SetTotal(int64(p.total), false)
+			return
+		}
+
+		p.bar.IncrBy(1)
+	case verify.DatabaseHashFinished:
+		if p.bar == nil {
+			return
+		}
+
+		if e.Err != nil {
+			// cancel progress bar render waiting
+			p.progress.Abort(p.bar, false)
+		} else {
+			p.bar.IncrBy(1)
+		}
+
+		// wait for progress bar to finish rendering
+		p.progress.Wait()
+		p.progress, p.bar = nil, nil
+	case anchor.AnchorStarted:
+		fmt.Printf("Verifying %s of `%s`...\n", anchorCheckNames[e.Result.Check], e.Result.Branch)
+	case anchor.AnchorFinished:
+		r := e.Result
+
+		for _, p := range r.Providers {
+			if p.Agreed {
+				continue
+			}
+
+			if p.Error != "" {
+				fmt.Printf("Provider `%s` of `%s` disagrees: %s\n", p.URI, r.Branch, p.Error)
+			} else {
+				fmt.Printf("Provider `%s` of `%s` disagrees: `%s`\n", p.URI, r.Branch, p.ActualValue)
+			}
+		}
+
+		if !r.Skipped && r.Status == status.VerificationStatusVerified {
+			fmt.Printf("%s of `%s` is `%s`\n", anchorCheckNames[r.Check], r.Branch, r.ActualValue)
+		}
+	}
+}
@@ -26,6 +26,7 @@ package main
 
 import (
 	"os"
+	"time"
 
 	cli "gopkg.in/urfave/cli.v2"
 )
@@ -35,58 +36,24 @@ var (
 )
 
 const (
-	cmdName                       = "provendb-verify"
-	versionIDCurrent              = "current"
-	defaultMongoDBPort            = "27017"
-	defaultMongoDBURI             = "mongodb://localhost:" + defaultMongoDBPort
-	defaultErrorHelpMsg           = "try '" + cmdName + " -h' for more information"
-	defaultMaxPoolSize            = uint16(30)
-	docFilterFormatHelpMsg        = `MongoDB extended JSON format, such as, '{"_id": {"$oid": "5b6a6a1646e0fb00080aac8c"}}'`
-	idKey                         = "_id"
-	mongoDBSystemPrefix           = "system."
-	provenDBMetaPrefix            = "_provendb"
-	provenDBIgnoredSuffix         = "pdbignore"
-	provenDBVersionProofs         = provenDBMetaPrefix + "_versionProofs"
-	provenDBDocMetaKey            = provenDBMetaPrefix + "_metadata"
-	provenDBDocMetaIDKey          = provenDBDocMetaKey + "." + idKey
-	provenDBMinVersionKey         = "minVersion"
-	provenDBForgottenKey          = "forgotten"
-	provenDBDocMetaMinVersionKey  = provenDBDocMetaKey + "." + provenDBMinVersionKey
-	provenDBDocMetaMaxVersionKey  = provenDBDocMetaKey + ".maxVersion"
-	provenDBVersionKey            = "version"
-	provenDBVersionIDKey          = provenDBVersionKey + "Id"
-	provenDBVersionCurrent        = "current"
-	provenDBProofIDKey            = "proofId"
-	provenDBSubmittedKey          = "submitted"
-	provenDBStatusKey             = "status"
-	provenDBScopeKey              = "scope"
-	provenDBFilterKey             = "filter"
-	provenDBScopeCollection       = "collection"
-	provenDBScopeDatabase         = "database"
-	provenDBDetailsKey            = "details"
-	provenDBCollectionsKey        = "collections"
-	provenDBDetailsCollectionsKey = provenDBDetailsKey + "." + provenDBCollectionsKey
-	provenDBNameKey               = "name"
-	provenDBProofKey              = "proof"
-	provenDBHashKey               = "hash"
-	provenDBDocBranch             = "pdb_doc_branch"
-)
-
-type proofType string
-
-var (
-	debug,
-	skipDocCheck,
-	verifyAnchorIndependently bool
-	proofTypes = struct {
-		database proofType
-		document proofType
-		raw      proofType
-	}{
-		database: "database",
-		document: "document",
-		raw:      "raw",
-	}
+	cmdName                 = "provendb-verify"
+	versionIDCurrent        = "current"
+	defaultMongoDBPort      = "27017"
+	defaultMongoDBURI       = "mongodb://localhost:" + defaultMongoDBPort
+	defaultErrorHelpMsg     = "try '" + cmdName + " -h' for more information"
+	defaultMaxPoolSize      = uint16(30)
+	defaultBatchParallelism = 4
+	defaultServeAddr        = ":8080"
+	defaultShutdownTimeout  = 30 * time.Second
+	docFilterFormatHelpMsg  = `MongoDB extended JSON format, such as, '{"_id": {"$oid": "5b6a6a1646e0fb00080aac8c"}}'`
+	provenDBVersionKey      = "version"
+	provenDBVersionIDKey    = provenDBVersionKey + "Id"
+	provenDBVersionCurrent  = "current"
+	provenDBProofIDKey      = "proofId"
+	provenDBSubmittedKey    = "submitted"
+	provenDBStatusKey       = "status"
+	outputFormatText        = "text"
+	outputFormatJSON        = "json"
 )
 
 func main() {
@@ -118,128 +85,186 @@ COPYRIGHT:
    {{.Copyright}}{{end}}
 `
 
+	cli.HelpFlag = &cli.BoolFlag{
+		Name:    "help",
+		Aliases: []string{"h"},
+		Usage:   wrap("show this usage information"),
+	}
+
 	app := &cli.App{
 		Name:      "provendb-verify",
 		Version:   cmdVersion,
 		Usage:     "ProvenDB Open Source Verification CLI",
 		ArgsUsage: " ",
-		HideHelp:  true,
-		Flags: []cli.Flag{
-			// TODO: support dumpfile
-			// &cli.StringFlag{
-			// 	Name:  "file",
-			// 	Usage: wrap("specify a mongodump `File` as the verification target. In this case, other MongoDB authentication options are ignored"),
-			// },
-			&cli.StringFlag{
-				Name:  "uri",
-				Usage: wrap("specify a resolvable MongoDB `URI` connection string as the verification target. When using this option with others, such as '--ssl=false', other explicitly specified options will always take precedence"),
-				Value: defaultMongoDBURI,
-			},
-			&cli.StringFlag{
-				Name:  "host",
-				Usage: wrap("specify a MongoDB `HOST` as the verification target"),
-			},
-			&cli.StringFlag{
-				Name:  "port",
-				Usage: wrap("specify a MongoDB `PORT` as the verification target"),
-			},
-			&cli.BoolFlag{
-				Name:        "ssl",
-				Usage:       wrap("use SSL for the MongoDB connection"),
-				DefaultText: "",
-			},
-			&cli.StringFlag{
-				Name:    "username",
-				Aliases: []string{"u"},
-				Usage:   wrap("specify a `USERNAME` for the MongoDB authentication"),
+		// flag-only invocations, which predate the commands, are aliases of `verify`
+		Flags: hideFlags(joinFlags(
+			connectionFlags(),
+			versionFlags(),
+			documentFlags(),
+			hashFlags(),
+			verifyFlags(),
+			anchorFlags(),
+			proofFileFlags(),
+			[]cli.Flag{
+				&cli.BoolFlag{
+					Name:    "listVersions",
+					Aliases: []string{"ls"},
+					Usage:   wrap("list all the verifiable versions along with ProvenDB Proof IDs for the target MongoDB database"),
+				},
+				formatFlag(),
+				debugFlag(),
 			},
-			&cli.StringFlag{
-				Name:    "password",
-				Aliases: []string{"p"},
-				Usage:   wrap("specify a `PASSWORD` for the MongoDB authentication"),
+		)),
+		Action: action(handleCLI),
+		Commands: []*cli.Command{
+			{
+				Name:      "verify",
+				Usage:     "verify a database, a document, a Chainpoint Proof or a ProvenDB Proof Archive",
+				ArgsUsage: " ",
+				Flags: joinFlags(
+					connectionFlags(),
+					versionFlags(),
+					documentFlags(),
+					hashFlags(),
+					verifyFlags(),
+					anchorFlags(),
+					proofFileFlags(),
+					[]cli.Flag{formatFlag(), debugFlag()},
+				),
+				Action: action(handleVerify),
 			},
-			&cli.StringSliceFlag{
-				Name:        "ignoredCollections",
-				Usage:       wrap("specify a comma seperated list of ignored collections"),
-				DefaultText: "",
+			{
+				Name:      "batch",
+				Usage:     "verify every verifiable version of a database, optionally in a version or date range with certain Proof statuses",
+				ArgsUsage: " ",
+				Flags: joinFlags(
+					connectionFlags(),
+					batchFlags(),
+					hashFlags(),
+					verifyFlags(),
+					anchorFlags(),
+					[]cli.Flag{formatFlag(), debugFlag()},
+				),
+				Action: action(handleBatch),
 			},
-			&cli.StringFlag{
-				Name:    "authDatabase",
-				Aliases: []string{"adb"},
-				Usage:   wrap("specify a `DATABASE` to be used for authentication (ignored for a ProvenDB connection)"),
+			{
+				Name:      "serve",
+				Usage:     "serve verifications over an HTTP API. Chainpoint Proofs and ProvenDB Proof Archives can be posted to it, and the versions and documents of the database given by the connection options can be referenced",
+				ArgsUsage: " ",
+				Flags: joinFlags(
+					serveFlags(),
+					connectionFlags(),
+					hashFlags(),
+					verifyFlags(),
+					anchorFlags(),
+					[]cli.Flag{debugFlag()},
+				),
+				Action: action(handleServe),
 			},
-			&cli.StringFlag{
-				Name:    "database",
-				Aliases: []string{"db"},
-				Usage:   wrap("specify a `DATABASE` as the verification target (ignored for a ProvenDB connection)"),
+			{
+				Name:      "verifiers",
+				Usage:     "list the registered anchor verifiers, which are used to verify anchors independently",
+				ArgsUsage: " ",
+				Flags:     joinFlags(anchorFlags(), []cli.Flag{formatFlag()}),
+				Action:    action(handleVerifiers),
 			},
-			&cli.StringFlag{
-				Name:    provenDBProofIDKey,
-				Aliases: []string{"pid"},
-				Usage:   wrap("specify a ProvenDB Proof `ID` and use the version in that Proof as '--versionId'"),
+			{
+				Name:      "cache",
+				Usage:     "manage the anchor evidence cache",
+				ArgsUsage: " ",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "prune",
+						Usage:     "remove the cached anchor evidence, optionally only the ones fetched a while ago",
+						ArgsUsage: " ",
+						Flags: []cli.Flag{
+							cacheDirFlag(),
+							&cli.DurationFlag{
+								Name:  "olderThan",
+								Usage: wrap("specify a `DURATION` to only remove the evidence fetched longer than it ago, such as '720h'"),
+							},
+						},
+						Action: action(handleCachePrune),
+					},
+				},
 			},
-			&cli.StringFlag{
-				Name:    provenDBVersionIDKey,
-				Aliases: []string{"vid"},
-				Usage:   wrap("specify a `VERSION` to be verified. Use '" + versionIDCurrent + "' to verify the most recent version"),
-				Value:   versionIDCurrent,
+			{
+				Name:      "list",
+				Usage:     "list all the verifiable versions along with ProvenDB Proof IDs for the target MongoDB database",
+				ArgsUsage: " ",
+				Flags: joinFlags(
+					connectionFlags(),
+					[]cli.Flag{formatFlag(), debugFlag()},
+				),
+				Action: action(handleList),
 			},
-			&cli.StringFlag{
-				Name:    "in",
-				Aliases: []string{"i"},
-				Usage:   wrap("specify a `PATH` to a ProvenDB Proof Archive (.zip) or an external Chainpoint Proof either in base64 (.txt) or JSON (.json). The (.txt) or (.json) will be used to verify the database or document, instead of using the stored one in ProvenDB. If the database or document is not specified, the (.txt) or (.json) itself will only be verified. You can use '--out' to output such (.txt) or (.json)"),
+			{
+				Name:      "export",
+				Usage:     "export the Chainpoint Proof stored in ProvenDB for a version without verifying it",
+				ArgsUsage: " ",
+				Flags: joinFlags(
+					connectionFlags(),
+					versionFlags(),
+					[]cli.Flag{
+						&cli.StringFlag{
+							Name:    "collection",
+							Aliases: []string{"col"},
+							Usage:   wrap("specify a collection `NAME` to export a collection level Proof that covers it"),
+						},
+						&cli.StringFlag{
+							Name:    "out",
+							Aliases: []string{"o"},
+							Usage:   wrap("specify a `PATH` to output the Chainpoint Proof. Then filename in the PATH must end with either '.json' (for JSON) or '.txt' (for compressed binary in base64)"),
+						},
+						debugFlag(),
+					},
+				),
+				Action: action(handleExport),
 			},
-			&cli.StringFlag{
-				Name:  "pubKey",
-				Usage: wrap("specify a `PATH` to a RSA public key (.pem) to verify the signature contained in a Proof"),
+			{
+				Name:      "inspect",
+				Usage:     "render the evaluated branch tree of a Chainpoint Proof without verifying it or making any network calls",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "in",
+						Aliases: []string{"i"},
+						Usage:   wrap("specify a `PATH` to a Chainpoint Proof either in base64 (.txt) or JSON (.json)"),
+					},
+					formatFlag(),
+				},
+				Action: action(handleInspect),
 			},
-			&cli.BoolFlag{
-				Name:    "listVersions",
-				Aliases: []string{"ls"},
-				Usage:   wrap("list all the verifiable versions along with ProvenDB Proof IDs for the target MongoDB database"),
+			{
+				Name:      "hash",
+				Usage:     "calculate the hash of a database or document version, which is the hash anchored by its Chainpoint Proof",
+				ArgsUsage: " ",
+				Flags: joinFlags(
+					connectionFlags(),
+					[]cli.Flag{
+						&cli.StringFlag{
+							Name:    provenDBVersionIDKey,
+							Aliases: []string{"vid"},
+							Usage:   wrap("specify a `VERSION` to be hashed. Use '" + versionIDCurrent + "' to hash the most recent verifiable version"),
+							Value:   versionIDCurrent,
+						},
+					},
+					documentFlags(),
+					hashFlags(),
+					[]cli.Flag{debugFlag()},
+				),
+				Action: action(handleHash),
 			},
-			&cli.StringFlag{
-				Name:    "collection",
-				Aliases: []string{"col"},
-				Usage:   wrap("specify the collection `NAME` of the document to be verified. When using this option, '--docFilter' must be provided to get that document"),
-			},
-			&cli.StringFlag{
-				Name:    "docFilter",
-				Aliases: []string{"df"},
-				Usage:   wrap("specify a `FILTER` to get the document as the verification target, which must be in " + docFilterFormatHelpMsg + ". When using this option, '--collection' must be provided. Ignoring this, the provided MongoDB database will be verified. This option can be combined with '--versionId' to verify a document in that specific version"),
-			},
-			&cli.StringFlag{
-				Name:    "out",
-				Aliases: []string{"o"},
-				Usage:   wrap("specify a `PATH` to output the Chainpoint Proof when verified. Then filename in the PATH must end with either '.json' (for JSON) or '.txt' (for compressed binary in base64)"),
-			},
-			&cli.BoolFlag{
-				Name:    "help",
-				Aliases: []string{"h"},
-				Usage:   wrap("show this usage information"),
-			},
-			&cli.BoolFlag{
-				Name:        "debug",
-				Usage:       wrap("print out debug information"),
-				Destination: &debug,
-			},
-			&cli.BoolFlag{
-				Name:        "skipDocCheck",
-				Usage:       wrap("skip checking document hash against document metadata"),
-				Destination: &skipDocCheck,
-			},
-			&cli.BoolFlag{
-				Name:        "verifyAnchorIndependently",
-				Usage:       wrap("verify a proof's anchor independently, which does not rely on the proof's anchor URI to do the verification"),
-				Value:       true,
-				Destination: &verifyAnchorIndependently,
-			},
-		},
-		Action: func(c *cli.Context) error {
-			os.Exit(handleCLI(c))
-			return nil
 		},
 	}
 
 	app.Run(os.Args)
 }
+
+// action adapts a handler returning an exit code to a `cli.ActionFunc`
+func action(handler func(c *cli.Context) int) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		os.Exit(handler(c))
+		return nil
+	}
+}
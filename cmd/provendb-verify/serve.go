@@ -0,0 +1,139 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T18:36:02+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T18:36:02+11:00
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/server"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	cli "gopkg.in/urfave/cli.v2"
+)
+
+func handleServe(c *cli.Context) int {
+	if c.NArg() > 0 {
+		return cliErrorf("No args should be provided")
+	}
+
+	// progress events are not rendered, as requests are verified at the same time
+	verifier, err := newVerifier(c, &cliOutput{format: outputFormatJSON})
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	verifier.Anchor.VerifyIndependently = c.Bool("verifyAnchorIndependently")
+
+	opts, err := verifyOptions(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	if c.Int("maxBodySize") < 1 {
+		return cliErrorf("'--maxBodySize' must be >= 1")
+	}
+
+	if c.Duration("timeout") <= 0 {
+		return cliErrorf("'--timeout' must be positive")
+	}
+
+	cs, err := connString(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var database *mongo.Database
+
+	// the database references are only served when a database is given
+	if cs.Database != "" {
+		database, err = connect(ctx, cs)
+		if err != nil {
+			return cliErrorf(err.Error())
+		}
+	}
+
+	srv := &http.Server{
+		Addr: c.String("listen"),
+		Handler: server.New(server.Config{
+			Verifier:    verifier,
+			Options:     opts,
+			Database:    database,
+			MaxBodySize: int64(c.Int("maxBodySize")) << 20,
+			Timeout:     c.Duration("timeout"),
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		// leave enough time to write the report of a verification that has timed out
+		WriteTimeout: c.Duration("timeout") + time.Minute,
+		IdleTimeout:  2 * time.Minute,
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	if database != nil {
+		fmt.Printf("Serving verifications of database `%s` on `%s`...\n", database.Name(), srv.Addr)
+	} else {
+		fmt.Printf("Serving verifications on `%s`...\n", srv.Addr)
+	}
+
+	select {
+	case err := <-errs:
+		return cliErrorf("failed to serve: %s", err)
+	case sig := <-sigs:
+		fmt.Printf("Received %s, shutting down...\n", sig)
+	}
+
+	sCtx, sCancel := context.WithTimeout(ctx, defaultShutdownTimeout)
+	defer sCancel()
+
+	// wait for the ongoing verifications to finish
+	err = srv.Shutdown(sCtx)
+	if err != nil {
+		return cliErrorf("failed to shut down gracefully: %s", err)
+	}
+
+	if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return cliErrorf("failed to serve: %s", err)
+	}
+
+	return 0
+}
@@ -0,0 +1,94 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T19:45:30+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T19:45:30+11:00
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
+	cli "gopkg.in/urfave/cli.v2"
+)
+
+func handleVerifiers(c *cli.Context) int {
+	out, err := setup(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	cfg, err := anchorConfig(c)
+	if err != nil {
+		return cliErrorf("%s", err)
+	}
+
+	type verifierInfo struct {
+		anchor.Registration
+		// Endpoint is the endpoint of an anchor type, whose tokens are not shown
+		Endpoint *anchor.Endpoint `json:"endpoint,omitempty"`
+	}
+
+	var infos []verifierInfo
+
+	for _, r := range anchor.DefaultRegistry.Registrations() {
+		info := verifierInfo{Registration: r}
+
+		if r.Kind == anchor.KindAnchorType {
+			e := cfg.Endpoint(r.Key)
+			e.Token, e.URL = "", anchor.RedactURL(e.URL)
+
+			e.Alternates = append([]anchor.Endpoint(nil), e.Alternates...)
+			for i, a := range e.Alternates {
+				e.Alternates[i].Token, e.Alternates[i].URL = "", anchor.RedactURL(a.URL)
+			}
+
+			info.Endpoint = &e
+		}
+
+		infos = append(infos, info)
+	}
+
+	if out.format == outputFormatJSON {
+		data, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return cliErrorf("failed to output anchor verifiers: %s", err)
+		}
+
+		fmt.Println(string(data))
+		return 0
+	}
+
+	fmt.Printf("%-12s\t%-18s\t%-52s\t%-8s\t%s\n", "kind", "key", "verifier", "network", "endpoint")
+	for _, i := range infos {
+		var network, endpointURL string
+
+		if i.Endpoint != nil {
+			network, endpointURL = i.Endpoint.Network, i.Endpoint.URL
+		}
+
+		fmt.Printf("%-12s\t%-18s\t%-52s\t%-8s\t%s\n", i.Kind, i.Key, i.Name, network, endpointURL)
+	}
+
+	return 0
+}
@@ -27,114 +27,215 @@ package anchor
 import (
 	"context"
 	"encoding/hex"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/SouthbankSoftware/provendb-verify/pkg/httputil"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"golang.org/x/sync/errgroup"
 )
 
 var (
-	bcToken = ""
-	// VerifyAnchorIndependently indicates whether to verify a proof's anchor independently, which
-	// does not rely on the proof's anchor URI to do the verification
-	VerifyAnchorIndependently = false
-	reProvenDBAnchorURI       = regexp.MustCompile(`/(\w+)/([\da-f]+)$`)
+	reProvenDBAnchorURI = regexp.MustCompile(`/(\w+)/([\da-f]+)$`)
 )
 
 const (
-	endpointEth           = "https://rinkeby.infura.io/v3/ba25a62205f24e5bb74d4f9738910a83"
-	endpointEthMainnet    = "https://mainnet.infura.io/v3/bb4fefecb7964761aa5462b092d54c00"
-	endpointEthElastos    = "https://mainrpc.elaeth.io"
-	endpointHedera        = "https://api.testnet.kabuto.sh/v1/"
-	endpointHederaMainnet = "https://api.kabuto.sh/v1/"
+	btcAnchorBranch = "btc_anchor_branch"
+	// btcAnchorBranchType is the anchor type whose endpoint is used by a `btc_anchor_branch` when
+	// its network can't be detected
+	btcAnchorBranchType = "btc_mainnet"
 )
 
-func init() {
-	if v, ok := os.LookupEnv("PROVENDB_VERIFY_BCTOKEN"); ok {
-		bcToken = v
+// btcNetworkAnchorTypes are the anchor types whose endpoints are used by a `btc_anchor_branch`,
+// keyed by the Chainpoint anchor types of its anchors
+var btcNetworkAnchorTypes = map[string]string{
+	"btc":  "btc_mainnet",
+	"tbtc": "btc",
+}
+
+// Check types of a `Result`
+const (
+	CheckURI                = "uri"
+	CheckBtcTxOpReturn      = "btc_tx_op_return"
+	CheckBtcBlockMerkleRoot = "btc_block_merkle_root"
+	CheckEthTxData          = "eth_tx_data"
+	CheckHederaTxMemo       = "hedera_tx_memo"
+)
+
+// Result represents the result of a single check made against an anchor
+type Result struct {
+	// Branch is the label of the branch that contains the anchor
+	Branch string `json:"branch"`
+	// Type is the anchor type, such as `cal`, `btc` and `eth`
+	Type string `json:"type,omitempty"`
+	// AnchorID is the anchor ID, such as a Calendar block height or a Bitcoin block height
+	AnchorID string `json:"anchorId,omitempty"`
+	// Check is the type of the check, such as `uri` and `btc_tx_op_return`
+	Check string `json:"check"`
+	// URI is the location used to get the actual value
+	URI string `json:"uri,omitempty"`
+	// Skipped indicates whether the check is skipped, such as for a Chainpoint Calendar URI
+	Skipped bool `json:"skipped,omitempty"`
+	// ExpectedValue is the value calculated from the Proof
+	ExpectedValue string `json:"expectedValue"`
+	// ActualValue is the value got from the URI
+	ActualValue string `json:"actualValue,omitempty"`
+	// BlockHash is the hash of the block that includes the anchoring transaction when known
+	BlockHash string `json:"blockHash,omitempty"`
+	// BlockNumber is the number of the block that includes the anchoring transaction when known
+	BlockNumber int64 `json:"blockNumber,omitempty"`
+	// AnchoredAt is the time of the block or the consensus that includes the anchoring transaction,
+	// by which the anchored data existed. It is nil when unknown
+	AnchoredAt *time.Time `json:"anchoredAt,omitempty"`
+	// Providers are the answers of the providers queried for a quorum, where the ones that do not
+	// agree are the disagreements. It is empty when the endpoint has no alternates
+	Providers []ProviderResult `json:"providers,omitempty"`
+	// Status is the verification status of the check
+	Status status.VerificationStatus `json:"status"`
+	// Error is the reason when the check is not verified
+	Error string `json:"error,omitempty"`
+	// Code is the machine-readable reason when the check is not verified
+	Code status.Code `json:"code,omitempty"`
+	// Location is where the anchor is in the Proof
+	Location *status.Location `json:"location,omitempty"`
+	// StartedAt is the time when the check started
+	StartedAt time.Time `json:"startedAt"`
+	// Duration is the time taken by the check in nanoseconds
+	Duration time.Duration `json:"duration"`
+}
+
+type verifier struct {
+	cfg     *Config
+	mu      sync.Mutex
+	results []*Result
+}
+
+// record records the result of a check started at the given time. It returns the given error as
+// a `VerificationStatusError` with its code and location filled in
+func (v *verifier) record(r Result, start time.Time, actualValue interface{}, err error) error {
+	r.StartedAt = start
+	r.Duration = time.Since(start)
+
+	if actualValue != nil {
+		r.ActualValue = fmt.Sprint(actualValue)
 	}
 
-	if v, ok := os.LookupEnv("PROVENDB_VERIFY_VERIFY_ANCHOR_INDEPENDENTLY"); ok {
-		b, err := strconv.ParseBool(v)
-		if err != nil {
-			panic(fmt.Errorf("invalid `PROVENDB_VERIFY_VERIFY_ANCHOR_INDEPENDENTLY`: %s", err))
+	if err != nil {
+		se := status.Of(err)
+		if se == nil {
+			// the anchor value cannot be got
+			se = status.NewCodedError(status.VerificationStatusUnverifiable, status.CodeAnchorUnreachable, err)
+			err = se
+		}
+
+		if se.Code == "" {
+			if se.Status == status.VerificationStatusFalsified {
+				se.Code = status.CodeAnchorValueMismatch
+			} else {
+				se.Code = status.CodeAnchorUnreachable
+			}
+		}
+
+		if se.Location == nil {
+			se.Location = r.Location
 		}
 
-		VerifyAnchorIndependently = b
+		r.Error = err.Error()
+		r.Status = se.Status
+		r.Code = se.Code
+	} else {
+		r.Status = status.VerificationStatusVerified
 	}
+
+	v.mu.Lock()
+	v.results = append(v.results, &r)
+	v.mu.Unlock()
+
+	event.Emit(v.cfg.Events, AnchorFinished{r})
+
+	return err
 }
 
-// ShowProgress is the flag indicates whether to show anchor verification progress as log messages
-var ShowProgress = true
+// start notifies that the check described by the given result template is started
+func (v *verifier) start(r Result) {
+	event.Emit(v.cfg.Events, AnchorStarted{r})
+}
+
+// Verify verifies anchor info in a given evaluated Proof JSON using the given config and returns
+// the results of all the checks made against the anchors. The returned error is nil if succeed.
+// When the proof is verifiable and falsified, the returned error is a type of
+// `VerificationStatusError`.
+func Verify(ctx context.Context, cfg *Config, evaluatedProof *model.EvaluatedProof) (results []*Result, er error) {
+	v := &verifier{cfg: cfg}
 
-// Verify verifies anchor info in a given evaluated Proof JSON and returns nil if succeed, and error
-// otherwise. When the proof is verifiable and falsified, the returned error is a type of `VerificationStatusError`.
-func Verify(ctx context.Context, evaluatedProof interface{}) (er error) {
 	defer func() {
-		if r := recover(); r != nil {
-			// type assertion panics are treated as `VerificationStatusFalsified`
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
-		}
+		results = v.results
+	}()
 
+	defer func() {
 		if er != nil {
 			// add error prefix
-			err := fmt.Errorf("failed to verify Proof anchors: %s", er)
-
-			if se, ok := er.(*status.VerificationStatusError); ok {
-				se.Err = err
+			if se := status.Of(er); se != nil {
+				se.Err = fmt.Errorf("failed to verify Proof anchors: %w", se.Err)
+				er = se
 			} else {
-				er = err
+				er = fmt.Errorf("failed to verify Proof anchors: %w", er)
 			}
 		}
 	}()
 
-	return verifyBranches(ctx,
-		evaluatedProof.(map[string]interface{})["branches"].([]interface{}))
+	er = v.verifyBranches(ctx, nil, evaluatedProof.Branches)
+	return
 }
 
-func verifyBranches(ctx context.Context, branches []interface{}) (er error) {
+// verifyBranches verifies the given branches, whose parent branches have the given labels
+func (v *verifier) verifyBranches(ctx context.Context, parents []string, branches []model.EvaluatedBranch) (er error) {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
-		}
-	}()
-
 	eg, egCtx := errgroup.WithContext(ctx)
 
-	for _, branch := range branches {
-		branch := branch.(map[string]interface{})
+	for i := range branches {
+		branch := &branches[i]
+		labels := append(append([]string{}, parents...), branch.Label)
 
-		switch l := branch["label"].(string); l {
-		case "btc_anchor_branch":
+		if av := v.cfg.registry().Branch(branch.Label); av != nil {
 			eg.Go(func() error {
-				return verifyBitcoinBranch(egCtx, branch)
+				return av.Verify(egCtx, &Checker{v}, Target{
+					Labels: labels,
+					Branch: branch,
+					Result: Result{
+						Branch:   branch.Label,
+						Location: &status.Location{Branches: labels, Op: -1},
+					},
+				})
 			})
-		default:
+		} else {
 			eg.Go(func() error {
-				return verifyBranch(egCtx, branch, l)
+				return v.verifyBranch(egCtx, labels, branch)
 			})
 		}
 
-		if bsI := branch["branches"]; bsI != nil {
-			bs := bsI.([]interface{})
-
+		if branch.Branches != nil {
 			eg.Go(func() error {
-				return verifyBranches(egCtx, bs)
+				return v.verifyBranches(egCtx, labels, branch.Branches)
 			})
 		}
 	}
@@ -142,327 +243,847 @@ func verifyBranches(ctx context.Context, branches []interface{}) (er error) {
 	return eg.Wait()
 }
 
-func verifyBranch(ctx context.Context, branch map[string]interface{}, label string) (er error) {
+func (v *verifier) verifyBranch(ctx context.Context, labels []string, branch *model.EvaluatedBranch) (er error) {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
-		}
-	}()
-
-	if ShowProgress {
-		fmt.Printf("Verifying `%s`...\n", label)
-	}
-
-	anchors := branch["anchors"].([]interface{})
-
 	eg, egCtx := errgroup.WithContext(ctx)
 
-	for _, anchor := range anchors {
-		anchor := anchor.(map[string]interface{})
-		uris := anchor["uris"].([]interface{})
-		expectedValue := anchor["expected_value"].(string)
+	for _, anchor := range branch.Anchors {
+		anchor := anchor
+		res := newResult(labels, &anchor)
 
 		eg.Go(func() (er error) {
-			return verifyAnchorURIs(egCtx, uris, expectedValue)
+			return v.verifyAnchorURIs(egCtx, res, anchor.URIs, anchor.ExpectedValue)
 		})
 	}
 
 	return eg.Wait()
 }
 
-func verifyBitcoinBranch(ctx context.Context, branch map[string]interface{}) (er error) {
+func (v *verifier) verifyBitcoinBranch(ctx context.Context, labels []string, branch *model.EvaluatedBranch) (er error) {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
-		}
-	}()
-
-	if ShowProgress {
-		fmt.Println("Verifying Bitcoin anchor...")
+	if branch.BtcTxID == "" || branch.OpReturnValue == "" {
+		return status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeSchemaInvalid,
+			fmt.Errorf("`%s` doesn't contain a Bitcoin transaction", btcAnchorBranch),
+		).At(&status.Location{Branches: labels, Op: -1})
 	}
 
-	anchors := branch["anchors"].([]interface{})
-
 	eg, egCtx := errgroup.WithContext(ctx)
+	branchType := v.btcAnchorBranchType(branch.Anchors)
 
-	for _, anchor := range anchors {
-		anchor := anchor.(map[string]interface{})
+	var (
+		// roots waits for the Bitcoin block merkle root checks
+		roots sync.WaitGroup
+		// verifiedRoots is the number of the merkle roots verified against the headers
+		verifiedRoots int32
+	)
 
-		blockHeight := anchor["anchor_id"].(string)
-		uris := anchor["uris"].([]interface{})
-		expectedValue := anchor["expected_value"].(string)
+	for _, anchor := range branch.Anchors {
+		anchor := anchor
+		res := newResult(labels, &anchor)
 
 		eg.Go(func() error {
-			return verifyAnchorURIs(egCtx, uris, expectedValue)
+			return v.verifyAnchorURIs(egCtx, res, anchor.URIs, anchor.ExpectedValue)
 		})
 
-		eg.Go(func() error {
-			return verifyBitcoinBlockMerkleRoot(egCtx, blockHeight, expectedValue)
-		})
+		if _, ok := btcNetworkAnchorTypes[anchor.Type]; ok {
+			anchorType := v.btcAnchorBranchType([]model.EvaluatedAnchor{anchor})
+
+			roots.Add(1)
+			eg.Go(func() error {
+				defer roots.Done()
+
+				err := v.verifyBitcoinBlockMerkleRoot(egCtx, res, anchor.AnchorID, anchor.ExpectedValue,
+					v.cfg.Endpoint(anchorType))
+				if err == nil && v.cfg.BtcHeaders != nil {
+					atomic.AddInt32(&verifiedRoots, 1)
+				}
+
+				return err
+			})
+		}
 	}
 
-	txID := branch["btcTxId"].(string)
-	expectedValue := branch["opReturnValue"].(string)
+	txID := branch.BtcTxID
+	expectedValue := branch.OpReturnValue
 
 	eg.Go(func() error {
-		return verifyBtcTxnData(egCtx, txID, expectedValue, true)
+		res := Result{
+			Branch:   btcAnchorBranch,
+			Type:     branchType,
+			Location: &status.Location{Branches: labels, Op: -1},
+		}
+
+		if v.cfg.BtcHeaders != nil {
+			roots.Wait()
+
+			if atomic.LoadInt32(&verifiedRoots) > 0 {
+				// the transaction is proven to be in the blocks whose merkle roots are verified
+				// against the headers
+				res.Check = CheckBtcTxOpReturn
+				res.ExpectedValue = expectedValue
+				res.Skipped = true
+				return v.record(res, time.Now(), nil, nil)
+			}
+		}
+
+		return v.verifyBtcTxnData(egCtx, res, txID, expectedValue, v.cfg.Endpoint(branchType))
 	})
 
 	return eg.Wait()
 }
 
-func verifyBitcoinBlockMerkleRoot(ctx context.Context, blockHeight string, expectedValue string) (er error) {
-	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
+// btcAnchorBranchType returns the anchor type whose endpoint is used to verify a
+// `btc_anchor_branch` with the given anchors. The configured one takes precedence over the one
+// detected from the first Bitcoin anchor
+func (v *verifier) btcAnchorBranchType(anchors []model.EvaluatedAnchor) string {
+	if v.cfg.BtcAnchorBranchType != "" {
+		return v.cfg.BtcAnchorBranchType
+	}
+
+	for _, anchor := range anchors {
+		if t, ok := btcNetworkAnchorTypes[anchor.Type]; ok {
+			return t
 		}
-	}()
+	}
 
-	if ShowProgress {
-		fmt.Println("Verifying Bitcoin block merkle root...")
+	return btcAnchorBranchType
+}
+
+func (v *verifier) verifyBitcoinBlockMerkleRoot(ctx context.Context, res Result, blockHeight string, expectedValue string, ep Endpoint) (er error) {
+	if v.cfg.BtcHeaders != nil {
+		return v.verifyBitcoinHeader(res, blockHeight, expectedValue, ep)
 	}
 
-	json, err := httputil.HTTPGetJSON(ctx, fmt.Sprintf("https://api.blockcypher.com/v1/btc/main/blocks/%s?txstart=1&limit=1&token=%s", blockHeight, bcToken))
+	var (
+		start       = time.Now()
+		actualValue interface{}
+	)
+
+	res.Check = CheckBtcBlockMerkleRoot
+	res.ExpectedValue = expectedValue
+
+	backend, err := newBtcBackend(ep)
 	if err != nil {
-		return err
+		return v.record(res, start, nil, err)
 	}
 
-	jsonM := json.(map[string]interface{})
+	res.URI = backend.blockURI(blockHeight)
+	v.start(res)
+
+	defer func() {
+		er = v.record(res, start, actualValue, er)
+	}()
+
+	var height int64
 
-	if errStr := jsonM["error"]; errStr != nil {
-		return errors.New(errStr.(string))
+	if ep.minConfirmations() > 0 {
+		height, err = strconv.ParseInt(blockHeight, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Bitcoin block height `%s`: %s", blockHeight, err)
+		}
+	}
+
+	a, err := v.lookupQuorum(&res, ep, func(p Endpoint, keySuffix string) (a providerAnswer) {
+		backend, err := newBtcBackend(p)
+		if err != nil {
+			a.err = err
+			return
+		}
+
+		block := &btcBlock{}
+		a.uri, a.value = backend.blockURI(blockHeight), block
+		a.evidence, a.err = v.lookupRaw(ctx, evidenceKey("btc", res, p, "block", blockHeight)+keySuffix, a.uri, block,
+			block.parse,
+			func() (*rawEvidence, error) {
+				return backend.block(ctx, blockHeight)
+			})
+		a.actual = block.MerkleRoot
+
+		if a.err == nil && ep.minConfirmations() > 0 {
+			var tip int64
+
+			tip, a.confirmationsErr = v.lookupCount(evidenceKey("btc", res, p, "tip", "")+keySuffix, backend.tipURI(),
+				func() (int64, error) {
+					return backend.tipHeight(ctx)
+				})
+			a.confirmations = confirmations(tip, height)
+		}
+		return
+	})
+	if err != nil {
+		return err
 	}
 
-	actualValue := jsonM["mrkl_root"]
+	block := a.value.(*btcBlock)
+	actualValue = a.actual
 
 	if actualValue != expectedValue {
-		return status.NewVerificationStatusError(
+		return status.NewCodedError(
 			status.VerificationStatusFalsified,
+			status.CodeAnchorValueMismatch,
 			fmt.Errorf("Bitcoin block height `%s` has merkle root `%s`, but expect `%s`", blockHeight, actualValue, expectedValue),
 		)
 	}
 
-	if ShowProgress {
-		fmt.Printf("Bitcoin block height `%s` has merkle root `%s`\n", blockHeight, actualValue)
+	res.AnchoredAt = timeOrNil(block.Time)
+
+	if ep.minConfirmations() > 0 {
+		if a.confirmationsErr != nil {
+			return a.confirmationsErr
+		}
+
+		err = checkConfirmations(fmt.Sprintf("Bitcoin block height `%s`", blockHeight), a.confirmations,
+			ep.minConfirmations())
+		if err != nil {
+			return err
+		}
+
+		v.cacheConfirmed(a.evidence, "btc", a.confirmations)
 	}
 
 	return nil
 }
 
-func verifyBtcTxnData(ctx context.Context, txnID, expectedValue string, mainnet bool) (er error) {
+// verifyBitcoinHeader verifies the Bitcoin block merkle root against the header chain offline,
+// which must be of the network of the given endpoint
+func (v *verifier) verifyBitcoinHeader(res Result, blockHeight string, expectedValue string, ep Endpoint) (er error) {
+	var (
+		start       = time.Now()
+		chain       = v.cfg.BtcHeaders
+		actualValue interface{}
+	)
+
+	res.Check = CheckBtcBlockMerkleRoot
+	res.URI = chain.uri(blockHeight)
+	res.ExpectedValue = expectedValue
+	v.start(res)
+
 	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
-		}
+		er = v.record(res, start, actualValue, er)
 	}()
 
-	if ShowProgress {
-		fmt.Println("Verifying Bitcoin transaction OP_RETURN...")
+	height, err := strconv.ParseInt(blockHeight, 10, 64)
+	if err != nil {
+		return status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeSchemaInvalid,
+			fmt.Errorf("invalid Bitcoin block height `%s`: %s", blockHeight, err),
+		)
 	}
 
-	var network string
+	if ep.Network != chain.Network {
+		return fmt.Errorf("Bitcoin headers in `%s` are of network `%s`, but expect `%s`",
+			chain.Filename, chain.Network, ep.Network)
+	}
 
-	if mainnet {
-		network = "main"
-	} else {
-		network = "test3"
+	root, err := chain.MerkleRoot(height)
+	if err != nil {
+		return err
 	}
 
-	json, err := httputil.HTTPGetJSON(ctx,
-		fmt.Sprintf("https://api.blockcypher.com/v1/btc/%s/txs/%s?token=%s",
-			network, txnID, bcToken))
+	actualValue = root
+
+	if root != expectedValue {
+		return status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeAnchorValueMismatch,
+			fmt.Errorf("Bitcoin block height `%s` has merkle root `%s`, but expect `%s`", blockHeight, root, expectedValue),
+		)
+	}
+
+	blockTime, err := chain.BlockTime(height)
 	if err != nil {
 		return err
 	}
 
-	jsonM := json.(map[string]interface{})
+	res.AnchoredAt = &blockTime
+
+	return checkConfirmations(fmt.Sprintf("Bitcoin block height `%s` in `%s`", blockHeight, chain.Filename),
+		chain.Confirmations(height), ep.minConfirmations())
+}
+
+// timeOrNil returns the given time, or nil when it is zero
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// checkConfirmations checks whether the anchored transaction or block with the given description
+// has the minimum number of confirmations
+func checkConfirmations(desc string, n, minConfirmations int64) error {
+	if n < minConfirmations {
+		return status.NewCodedError(
+			status.VerificationStatusUnverifiable,
+			status.CodeInsufficientConfirmations,
+			fmt.Errorf("%s has %d confirmations, but require %d", desc, n, minConfirmations),
+		)
+	}
+
+	return nil
+}
+
+func (v *verifier) verifyBtcTxnData(ctx context.Context, res Result, txnID, expectedValue string, ep Endpoint) (er error) {
+	var (
+		start       = time.Now()
+		actualValue interface{}
+	)
 
-	if errStr := jsonM["error"]; errStr != nil {
-		return errors.New(errStr.(string))
+	res.Check = CheckBtcTxOpReturn
+	res.ExpectedValue = expectedValue
+
+	backend, err := newBtcBackend(ep)
+	if err != nil {
+		return v.record(res, start, nil, err)
 	}
 
-	actualValue := jsonM["outputs"].([]interface{})[0].(map[string]interface{})["data_hex"]
+	res.URI = backend.txURI(txnID)
+	v.start(res)
+
+	defer func() {
+		er = v.record(res, start, actualValue, er)
+	}()
+
+	a, err := v.lookupQuorum(&res, ep, func(p Endpoint, keySuffix string) (a providerAnswer) {
+		backend, err := newBtcBackend(p)
+		if err != nil {
+			a.err = err
+			return
+		}
+
+		tx := &btcTx{}
+		a.uri, a.value = backend.txURI(txnID), tx
+		a.evidence, a.err = v.lookupRaw(ctx, evidenceKey("btc", res, p, "tx", txnID)+keySuffix, a.uri, tx,
+			func(r *rawEvidence) error {
+				return tx.parse(r, txnID)
+			},
+			func() (*rawEvidence, error) {
+				return backend.tx(ctx, txnID)
+			})
+		a.actual = tx.OpReturn
+
+		if a.err == nil && ep.minConfirmations() > 0 {
+			a.confirmations, a.confirmationsErr = v.lookupCount(evidenceKey("btc", res, p, "confirmations", txnID)+keySuffix, a.uri,
+				func() (int64, error) {
+					return backend.txConfirmations(ctx, txnID)
+				})
+		}
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	tx := a.value.(*btcTx)
+	actualValue = a.actual
 
 	if actualValue != expectedValue {
-		return status.NewVerificationStatusError(
+		return status.NewCodedError(
 			status.VerificationStatusFalsified,
+			status.CodeAnchorValueMismatch,
 			fmt.Errorf("Bitcoin transaction `%s` has OP_RETURN `%s`, but expect `%s`", txnID, actualValue, expectedValue),
 		)
 	}
 
-	if ShowProgress {
-		fmt.Printf("Bitcoin transaction `%s` has OP_RETURN `%s`\n", txnID, actualValue)
+	res.AnchoredAt = timeOrNil(tx.BlockTime)
+
+	if ep.minConfirmations() > 0 {
+		if a.confirmationsErr != nil {
+			return a.confirmationsErr
+		}
+
+		err = checkConfirmations(fmt.Sprintf("Bitcoin transaction `%s`", txnID), a.confirmations,
+			ep.minConfirmations())
+		if err != nil {
+			return err
+		}
+
+		v.cacheConfirmed(a.evidence, "btc", a.confirmations)
 	}
 
 	return nil
 }
 
-func verifyEthTxnData(ctx context.Context, txnID, expectedValue, endpoint string) (er error) {
-	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
+// ethTxFormat is the format of the raw evidence of an Ethereum transaction, which is the results of
+// `eth_getTransactionByHash`, `eth_getTransactionReceipt` and `eth_getBlockByNumber`
+const ethTxFormat = "eth/tx"
+
+// ethTx represents an Ethereum transaction
+type ethTx struct {
+	// Data is the input data in hex
+	Data string `json:"data"`
+	// From is the sender address recovered from the signature
+	From common.Address `json:"from"`
+	// To is the recipient address, which is nil for a contract creation
+	To *common.Address `json:"to"`
+	// Succeeded indicates whether the receipt has a successful status
+	Succeeded bool `json:"succeeded"`
+	// BlockHash is the hash of the block that includes the transaction according to the receipt
+	BlockHash common.Hash `json:"blockHash"`
+	// BlockNumber is the number of the block that includes the transaction
+	BlockNumber int64 `json:"blockNumber"`
+	// Canonical indicates whether the block is on the canonical chain, where the block at the
+	// number has the same hash
+	Canonical bool `json:"canonical"`
+	// BlockTime is the time of the block that includes the transaction
+	BlockTime time.Time `json:"blockTime"`
+}
+
+// newEthTx creates an `ethTx` from the given signed transaction, its receipt and the header of the
+// canonical block at the receipt's block number
+func newEthTx(tx *types.Transaction, receipt *types.Receipt, header *types.Header) (*ethTx, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot recover the sender of the Ethereum transaction `%s`: %s", tx.Hash().Hex(), err)
+	}
+
+	return &ethTx{
+		Data:        hex.EncodeToString(tx.Data()),
+		From:        from,
+		To:          tx.To(),
+		Succeeded:   receipt.Status == types.ReceiptStatusSuccessful,
+		BlockHash:   receipt.BlockHash,
+		BlockNumber: receipt.BlockNumber.Int64(),
+		Canonical:   header.Hash() == receipt.BlockHash,
+		BlockTime:   time.Unix(int64(header.Time), 0).UTC(),
+	}, nil
+}
+
+// checkInclusion checks whether the transaction has succeeded in a block on the canonical chain
+func (t *ethTx) checkInclusion(txnID string) error {
+	if !t.Succeeded {
+		return status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeAnchorTxFailed,
+			fmt.Errorf("Ethereum transaction `%s` has failed in block %d", txnID, t.BlockNumber),
+		)
+	}
+
+	if !t.Canonical {
+		return status.NewCodedError(
+			status.VerificationStatusUnverifiable,
+			status.CodeAnchorNotCanonical,
+			fmt.Errorf("Ethereum transaction `%s` is in block `%s`, which is no longer block %d of the canonical chain",
+				txnID, t.BlockHash.Hex(), t.BlockNumber),
+		)
+	}
+
+	return nil
+}
+
+// checkAddresses checks whether the transaction is sent from and to the trusted addresses of the
+// given endpoint
+func (t *ethTx) checkAddresses(txnID string, ep Endpoint) error {
+	if len(ep.Senders) != 0 && !containsAddress(ep.Senders, &t.From) {
+		return status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeAnchorUntrusted,
+			fmt.Errorf("Ethereum transaction `%s` is sent from untrusted address `%s`", txnID, t.From.Hex()),
+		)
+	}
+
+	if len(ep.Recipients) != 0 && !containsAddress(ep.Recipients, t.To) {
+		to := "none"
+		if t.To != nil {
+			to = t.To.Hex()
 		}
-	}()
 
-	if ShowProgress {
-		fmt.Println("Verifying Ethereum transaction data...")
+		return status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeAnchorUntrusted,
+			fmt.Errorf("Ethereum transaction `%s` is sent to untrusted address `%s`", txnID, to),
+		)
 	}
 
-	client, err := ethclient.DialContext(ctx, endpoint)
-	if err != nil {
-		return err
+	return nil
+}
+
+// containsAddress reports whether the given address is one of the addresses in hex
+func containsAddress(addresses []string, addr *common.Address) bool {
+	if addr == nil {
+		return false
 	}
-	defer client.Close()
 
-	tx, pending, err := client.TransactionByHash(ctx, common.HexToHash(txnID))
+	for _, a := range addresses {
+		if common.IsHexAddress(a) && common.HexToAddress(a) == *addr {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *verifier) verifyEthTxnData(ctx context.Context, res Result, txnID, expectedValue string, ep Endpoint) (er error) {
+	var (
+		start = time.Now()
+		data  interface{}
+	)
+
+	res.Check = CheckEthTxData
+	res.ExpectedValue = expectedValue
+	v.start(res)
+
+	defer func() {
+		er = v.record(res, start, data, er)
+	}()
+
+	a, err := v.lookupQuorum(&res, ep, func(p Endpoint, keySuffix string) (a providerAnswer) {
+		tx := &ethTx{}
+		// the source URL is shown without its token
+		a.uri, a.value = p.URL+"#"+txnID, tx
+		a.evidence, a.err = v.lookupRaw(ctx, evidenceKey("eth", res, p, "tx", txnID)+keySuffix, a.uri, tx,
+			func(r *rawEvidence) error {
+				return tx.parse(r, txnID)
+			},
+			func() (*rawEvidence, error) {
+				err := p.checkToken()
+				if err != nil {
+					return nil, err
+				}
+
+				return getEthTx(ctx, p.resolvedURL(), txnID)
+			})
+		a.actual = tx.Data
+
+		if a.err == nil && ep.minConfirmations() > 0 {
+			var tip int64
+
+			tip, a.confirmationsErr = v.lookupCount(evidenceKey("eth", res, p, "tip", "")+keySuffix, p.URL+"#blockNumber",
+				func() (int64, error) {
+					return ethTipNumber(ctx, p.resolvedURL())
+				})
+			a.confirmations = confirmations(tip, tx.BlockNumber)
+		}
+		return
+	})
 	if err != nil {
 		return err
 	}
 
-	if pending {
-		return fmt.Errorf("the Ethereum transaction `%s` is still pending", txnID)
+	tx := a.value.(*ethTx)
+	res.BlockHash = tx.BlockHash.Hex()
+	res.BlockNumber = tx.BlockNumber
+
+	err = tx.checkInclusion(txnID)
+	if err != nil {
+		return err
 	}
 
-	data := hex.EncodeToString(tx.Data())
+	data = a.actual
 
 	if data != expectedValue {
-		return status.NewVerificationStatusError(
+		return status.NewCodedError(
 			status.VerificationStatusFalsified,
+			status.CodeAnchorValueMismatch,
 			fmt.Errorf("Ethereum transaction `%s` has data `%s`, but expect %s", txnID, data, expectedValue),
 		)
 	}
 
-	if ShowProgress {
-		fmt.Printf("Ethereum transaction `%s` has data `%s`\n", txnID, data)
+	err = tx.checkAddresses(txnID, ep)
+	if err != nil {
+		return err
+	}
+
+	res.AnchoredAt = timeOrNil(tx.BlockTime)
+
+	if ep.minConfirmations() > 0 {
+		if a.confirmationsErr != nil {
+			return a.confirmationsErr
+		}
+
+		err = checkConfirmations(fmt.Sprintf("Ethereum transaction `%s`", txnID), a.confirmations,
+			ep.minConfirmations())
+		if err != nil {
+			return err
+		}
+
+		v.cacheConfirmed(a.evidence, "eth", a.confirmations)
 	}
 
 	return nil
 }
 
-func verifyHederaTxnData(ctx context.Context, txnID, expectedValue string, mainnet bool) (er error) {
-	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
+// parse parses the raw evidence of the given transaction, whose signed transaction must hash to the
+// transaction ID, so the sender recovered from its signature can be trusted
+func (t *ethTx) parse(r *rawEvidence, txnID string) error {
+	if r.Format != ethTxFormat {
+		return r.errUnsupported()
+	}
+
+	var (
+		tx      types.Transaction
+		receipt types.Receipt
+		header  types.Header
+	)
+
+	err := r.response(0, &tx)
+	if err != nil {
+		return err
+	}
+
+	if hash := tx.Hash(); hash != common.HexToHash(txnID) {
+		return fmt.Errorf("Ethereum transaction `%s` hashes to `%s`", txnID, hash.Hex())
+	}
+
+	err = r.response(1, &receipt)
+	if err != nil {
+		return err
+	}
+
+	if receipt.TxHash != tx.Hash() || receipt.BlockNumber == nil {
+		return fmt.Errorf("invalid receipt of Ethereum transaction `%s`", txnID)
+	}
+
+	err = r.response(2, &header)
+	if err != nil {
+		return err
+	}
+
+	if header.Number == nil || header.Number.Cmp(receipt.BlockNumber) != 0 {
+		return fmt.Errorf("Ethereum transaction `%s` is in block %d, but got the header of block %d",
+			txnID, receipt.BlockNumber, header.Number)
+	}
+
+	result, err := newEthTx(&tx, &receipt, &header)
+	if err != nil {
+		return err
+	}
+
+	*t = *result
+
+	return nil
+}
+
+// getEthTx gets the raw evidence of the given transaction, which has the transaction, its receipt
+// and the header of the canonical block at the receipt's block number from the given endpoint
+func getEthTx(ctx context.Context, endpoint, txnID string) (*rawEvidence, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	hash := common.HexToHash(txnID)
+
+	var tx, receipt, header json.RawMessage
+
+	err = client.CallContext(ctx, &tx, "eth_getTransactionByHash", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if isJSONNull(tx) {
+		return nil, fmt.Errorf("the Ethereum transaction `%s` is not found", txnID)
+	}
+
+	err = client.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var block struct {
+		BlockNumber *hexutil.Big `json:"blockNumber"`
+	}
+
+	// the receipt is null until the transaction is included in a block
+	if !isJSONNull(receipt) {
+		err = json.Unmarshal(receipt, &block)
+		if err != nil {
+			return nil, err
 		}
-	}()
+	}
 
-	if ShowProgress {
-		fmt.Println("Verifying Hedera transaction data...")
+	if block.BlockNumber == nil {
+		return nil, fmt.Errorf("the Ethereum transaction `%s` is still pending", txnID)
 	}
 
-	type kabutoTxn struct {
-		Memo string `json:"memo"`
+	err = client.CallContext(ctx, &header, "eth_getBlockByNumber", block.BlockNumber, false)
+	if err != nil {
+		return nil, err
 	}
 
-	t := kabutoTxn{}
+	if isJSONNull(header) {
+		return nil, fmt.Errorf("the Ethereum block %s is not found", block.BlockNumber)
+	}
+
+	return newRawEvidence(ethTxFormat, tx, receipt, header), nil
+}
+
+// isJSONNull reports whether the given JSON is null
+func isJSONNull(raw json.RawMessage) bool {
+	return string(raw) == "null"
+}
 
-	endpoint := endpointHedera
-	if mainnet {
-		endpoint = endpointHederaMainnet
+// ethTipNumber gets the number of the most recent Ethereum block
+func ethTipNumber(ctx context.Context, endpoint string) (int64, error) {
+	client, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return 0, err
 	}
+	defer client.Close()
 
-	err := httputil.UnmarshalHTTPGetJSON(ctx, endpoint+"transaction/"+txnID, &t)
+	tip, err := client.BlockNumber(ctx)
 	if err != nil {
-		er = err
+		return 0, err
+	}
+
+	return int64(tip), nil
+}
+
+func (v *verifier) verifyHederaTxnData(ctx context.Context, res Result, txnID, expectedValue string, ep Endpoint) (er error) {
+	var (
+		start       = time.Now()
+		actualValue interface{}
+	)
+
+	res.Check = CheckHederaTxMemo
+	res.URI = hederaTxURI(ep, txnID)
+	res.ExpectedValue = expectedValue
+	v.start(res)
+
+	defer func() {
+		er = v.record(res, start, actualValue, er)
+	}()
+
+	a, err := v.lookupQuorum(&res, ep, func(p Endpoint, keySuffix string) (a providerAnswer) {
+		tx := &hederaTx{}
+		a.uri, a.value = hederaTxURI(p, txnID), tx
+		_, a.err = v.lookupRaw(ctx, evidenceKey("hedera", res, p, "tx", txnID)+keySuffix, a.uri, tx,
+			func(r *rawEvidence) error {
+				return tx.parse(r, txnID)
+			},
+			func() (*rawEvidence, error) {
+				return getHederaTx(ctx, p, txnID)
+			})
+		a.actual = tx.Memo
 		return
+	})
+	if err != nil {
+		return err
+	}
+
+	tx := a.value.(*hederaTx)
+
+	if tx.Result != hederaResultSuccess {
+		return status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeAnchorTxFailed,
+			fmt.Errorf("Hedera transaction `%s` has result `%s`, but expect `%s`", txnID, tx.Result, hederaResultSuccess),
+		)
 	}
-	actualValue := t.Memo
+
+	actualValue = a.actual
 
 	if actualValue != expectedValue {
-		return status.NewVerificationStatusError(
+		return status.NewCodedError(
 			status.VerificationStatusFalsified,
+			status.CodeAnchorValueMismatch,
 			fmt.Errorf("Hedera transaction `%s` has data `%s`, but expect `%s`", txnID, actualValue, expectedValue),
 		)
 	}
 
-	if ShowProgress {
-		fmt.Printf("Hedera transaction `%s` has data `%s`\n", txnID, actualValue)
-	}
+	res.AnchoredAt = timeOrNil(tx.ConsensusAt)
 
 	return nil
 }
 
-func verifyAnchorURIs(ctx context.Context, uris []interface{}, expectedValue string) (er error) {
+func (v *verifier) verifyAnchorURIs(ctx context.Context, res Result, uris []string, expectedValue string) (er error) {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			er = status.NewVerificationStatusError(status.VerificationStatusFalsified, r.(error))
-		}
-	}()
-
 	eg, egCtx := errgroup.WithContext(ctx)
 
 	for _, uri := range uris {
-		uri := uri.(string)
+		uri := uri
+		res := res
+		res.URI = uri
 
-		eg.Go(func() error {
+		eg.Go(func() (er error) {
 			if strings.Contains(uri, "/calendar") {
 				// ignore Chainpoint Calendar URIs
-				return nil
+				res.Check = CheckURI
+				res.ExpectedValue = expectedValue
+				res.Skipped = true
+				return v.record(res, time.Now(), nil, nil)
 			}
 
-			if VerifyAnchorIndependently {
+			if v.cfg.VerifyIndependently {
 				if m := reProvenDBAnchorURI.FindStringSubmatch(uri); m != nil {
-					anchorType := m[1]
-					txnID := m[2]
-
-					switch anchorType {
-					case "eth":
-						return verifyEthTxnData(egCtx, txnID, expectedValue, endpointEth)
-					case "eth_mainnet":
-						return verifyEthTxnData(egCtx, txnID, expectedValue, endpointEthMainnet)
-					case "eth_elastos":
-						return verifyEthTxnData(egCtx, txnID, expectedValue, endpointEthElastos)
-					case "btc":
-						return verifyBtcTxnData(egCtx, txnID, expectedValue, false)
-					case "btc_mainnet":
-						return verifyBtcTxnData(egCtx, txnID, expectedValue, true)
-					case "hedera":
-						return verifyHederaTxnData(egCtx, txnID, expectedValue, false)
-					case "hedera_mainnet":
-						return verifyHederaTxnData(egCtx, txnID, expectedValue, true)
+					if av := v.cfg.registry().AnchorType(m[1]); av != nil {
+						return av.Verify(egCtx, &Checker{v}, Target{
+							Result:        res,
+							TxID:          m[2],
+							ExpectedValue: expectedValue,
+						})
 					}
 				}
 
-				return status.NewVerificationStatusError(
+				err := status.NewCodedError(
 					status.VerificationStatusUnverifiable,
+					status.CodeAnchorUnsupported,
 					fmt.Errorf("verify anchor URI `%s` independently is not supported", uri),
 				)
+				res.Check = CheckURI
+				res.ExpectedValue = expectedValue
+				return v.record(res, time.Now(), nil, err)
 			}
 
-			body, err := httputil.HTTPGet(egCtx, uri)
-			if err != nil {
-				return err
-			}
-			defer body.Close()
+			var (
+				start       = time.Now()
+				actualValue interface{}
+			)
+
+			res.Check = CheckURI
+			res.ExpectedValue = expectedValue
+			v.start(res)
+
+			defer func() {
+				er = v.record(res, start, actualValue, er)
+			}()
 
-			bodyBytes, err := ioutil.ReadAll(body)
+			var value string
+
+			err := v.lookup(egCtx, uriEvidenceKey(uri), uri, &value, func() (interface{}, error) {
+				body, err := httputil.HTTPGet(egCtx, uri)
+				if err != nil {
+					return nil, err
+				}
+				defer body.Close()
+
+				bodyBytes, err := ioutil.ReadAll(body)
+				if err != nil {
+					return nil, err
+				}
+
+				return string(bodyBytes), nil
+			})
 			if err != nil {
 				return err
 			}
 
-			actualValue := string(bodyBytes)
+			actualValue = value
 
 			if actualValue != expectedValue {
-				return status.NewVerificationStatusError(
+				return status.NewCodedError(
 					status.VerificationStatusFalsified,
+					status.CodeAnchorValueMismatch,
 					fmt.Errorf("anchor URI %s returns %s, but expect %s", uri, actualValue, expectedValue),
 				)
 			}
@@ -473,3 +1094,13 @@ func verifyAnchorURIs(ctx context.Context, uris []interface{}, expectedValue str
 
 	return eg.Wait()
 }
+
+// newResult creates a result template for the given anchor in the branch with the given label path
+func newResult(labels []string, anchor *model.EvaluatedAnchor) Result {
+	return Result{
+		Branch:   labels[len(labels)-1],
+		Type:     anchor.Type,
+		AnchorID: anchor.AnchorID,
+		Location: &status.Location{Branches: labels, Op: anchor.Op},
+	}
+}
@@ -26,13 +26,39 @@ package anchor
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	log "github.com/sirupsen/logrus"
 )
 
+func newTestConfig(t *testing.T, verifyIndependently bool) *Config {
+	cfg, err := DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.VerifyIndependently = verifyIndependently
+	return cfg
+}
+
+func newTestVerifier(t *testing.T, verifyIndependently bool) *verifier {
+	return &verifier{cfg: newTestConfig(t, verifyIndependently)}
+}
+
 func Test_verifyAnchorURIs(t *testing.T) {
 	var canceledCtx context.Context
 
@@ -41,7 +67,7 @@ func Test_verifyAnchorURIs(t *testing.T) {
 
 	type args struct {
 		ctx           context.Context
-		uris          []interface{}
+		uris          []string
 		expectedValue string
 	}
 	tests := []struct {
@@ -53,7 +79,7 @@ func Test_verifyAnchorURIs(t *testing.T) {
 			"Verify Calendar anchor URIs",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://a.chainpoint.org/calendar/985635/hash",
 					"https://a.chainpoint.org/calendar/985635/hash",
 				},
@@ -65,7 +91,7 @@ func Test_verifyAnchorURIs(t *testing.T) {
 			"Verify Bitcoin anchor URIs",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://a.chainpoint.org/calendar/985814/data",
 				},
 				"c617f5faca34474bea7020d75c39cb8427a32145f9646586ecb9184002131ad9",
@@ -76,7 +102,7 @@ func Test_verifyAnchorURIs(t *testing.T) {
 			"Verify unknown URIs",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"http://skldfjklasdfk.com",
 				},
 				"",
@@ -87,7 +113,7 @@ func Test_verifyAnchorURIs(t *testing.T) {
 			"Verify with canceled context",
 			args{
 				canceledCtx,
-				[]interface{}{
+				[]string{
 					"http://skldfjklasdfk.com",
 				},
 				"",
@@ -98,7 +124,7 @@ func Test_verifyAnchorURIs(t *testing.T) {
 			"Verify with 404 status code",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://a.chainpoint.org/notexists",
 				},
 				"",
@@ -108,7 +134,7 @@ func Test_verifyAnchorURIs(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := verifyAnchorURIs(tt.args.ctx, tt.args.uris, tt.args.expectedValue)
+			err := newTestVerifier(t, false).verifyAnchorURIs(tt.args.ctx, Result{}, tt.args.uris, tt.args.expectedValue)
 
 			if err != nil {
 				log.Error(err)
@@ -132,15 +158,111 @@ func Test_verifyAnchorURIs(t *testing.T) {
 	}
 }
 
-func Test_verifyAnchorURIsIndependently(t *testing.T) {
-	VerifyAnchorIndependently = true
-	defer func() {
-		VerifyAnchorIndependently = false
-	}()
+func Test_verifyAnchorURIsResults(t *testing.T) {
+	const expectedValue = "592b3fbc543c066dcfdbb51a02f843ee312289694b0977e36b7c57e983c75ba8"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/falsified":
+			fmt.Fprint(w, "00")
+			return
+		case "/eth/unreachable":
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprint(w, expectedValue)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		uri        string
+		wantStatus status.VerificationStatus
+		wantActual string
+		wantErr    error
+	}{
+		{
+			"Record verified anchor URI",
+			ts.URL + "/eth/verified",
+			status.VerificationStatusVerified,
+			expectedValue,
+			nil,
+		},
+		{
+			"Record falsified anchor URI",
+			ts.URL + "/eth/falsified",
+			status.VerificationStatusFalsified,
+			"00",
+			status.ErrAnchorValueMismatch,
+		},
+		{
+			"Record unreachable anchor URI",
+			ts.URL + "/eth/unreachable",
+			status.VerificationStatusUnverifiable,
+			"",
+			status.ErrAnchorUnreachable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, false)
+
+			var events []event.Event
+			v.cfg.Events = event.SinkFunc(func(e event.Event) {
+				events = append(events, e)
+			})
+
+			loc := &status.Location{Branches: []string{"pdb_eth_anchor_branch", "eth_anchor_branch"}, Op: 3}
+
+			err := v.verifyAnchorURIs(context.Background(),
+				Result{Branch: "eth_anchor_branch", Type: "eth", Location: loc},
+				[]string{tt.uri}, expectedValue)
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("verifyAnchorURIs() error = %v, want nil", err)
+				}
+			} else {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("verifyAnchorURIs() error = %v, want %v", err, tt.wantErr)
+				}
+
+				if se := status.Of(err); se.Status != tt.wantStatus || se.Location != loc {
+					t.Errorf("verifyAnchorURIs() error = %#v", se)
+				}
+			}
+
+			if len(v.results) != 1 {
+				t.Fatalf("verifyAnchorURIs() recorded %d results, want 1", len(v.results))
+			}
 
+			r := v.results[0]
+
+			if r.Status != tt.wantStatus || r.ActualValue != tt.wantActual || r.URI != tt.uri ||
+				r.Branch != "eth_anchor_branch" || r.Check != CheckURI || r.Location != loc {
+				t.Errorf("verifyAnchorURIs() recorded %+v", r)
+			}
+
+			if len(events) != 2 {
+				t.Fatalf("verifyAnchorURIs() emitted %d events, want 2", len(events))
+			}
+
+			if e, ok := events[0].(AnchorStarted); !ok || e.Result.URI != tt.uri {
+				t.Errorf("verifyAnchorURIs() emitted %#v, want AnchorStarted", events[0])
+			}
+
+			if e, ok := events[1].(AnchorFinished); !ok || e.Result.Status != tt.wantStatus {
+				t.Errorf("verifyAnchorURIs() emitted %#v, want AnchorFinished", events[1])
+			}
+		})
+	}
+}
+
+func Test_verifyAnchorURIsIndependently(t *testing.T) {
 	type args struct {
 		ctx           context.Context
-		uris          []interface{}
+		uris          []string
 		expectedValue string
 	}
 	tests := []struct {
@@ -152,7 +274,7 @@ func Test_verifyAnchorURIsIndependently(t *testing.T) {
 			"Verify Calendar anchor URIs independently",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://a.chainpoint.org/calendar/985635/hash",
 					"https://b.chainpoint.org/calendar/1902589/data",
 				},
@@ -164,7 +286,7 @@ func Test_verifyAnchorURIsIndependently(t *testing.T) {
 			"Verify ETH anchor URI independently",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://anchor.provendb.com/eth/c86a9441a4fae4469f314d3520ecf1d62e670453e295add517c1d92d31ab3c6c",
 				},
 				"592b3fbc543c066dcfdbb51a02f843ee312289694b0977e36b7c57e983c75ba8",
@@ -175,7 +297,7 @@ func Test_verifyAnchorURIsIndependently(t *testing.T) {
 			"Verify ETH_MAINNET anchor URI independently",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://anchor.provendb.com/eth_mainnet/6cae5d7b052b92a6b4646fb1d00b5e379350e3125d7e80ddf45694eb98284e26",
 				},
 				"592b3fbc543c066dcfdbb51a02f843ee312289694b0977e36b7c57e983c75ba8",
@@ -186,7 +308,7 @@ func Test_verifyAnchorURIsIndependently(t *testing.T) {
 			"Verify BTC anchor URI independently",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://anchor.provendb.com/btc/7b0f1747e11a7d1369fcaa8c8c6e75e280c15a7a32968ec7d25082929d1df593",
 				},
 				"592b3fbc543c066dcfdbb51a02f843ee312289694b0977e36b7c57e983c75ba8",
@@ -197,7 +319,7 @@ func Test_verifyAnchorURIsIndependently(t *testing.T) {
 			"Verify BTC_MAINNET anchor URI independently",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://anchor.provendb.com/btc_mainnet/b60b2232592cad02ac084ecad6ab99c1a888c87bf56017c80a5f1d98d31c1ed6",
 				},
 				"592b3fbc543c066dcfdbb51a02f843ee312289694b0977e36b7c57e983c75ba8",
@@ -208,7 +330,7 @@ func Test_verifyAnchorURIsIndependently(t *testing.T) {
 			"Verify unknown anchor URIs independently",
 			args{
 				context.Background(),
-				[]interface{}{
+				[]string{
 					"https://sadfkasklfdkas",
 				},
 				"592b3fbc543c066dcfdbb51a02f843ee312289694b0977e36b7c57e983c75ba8",
@@ -218,7 +340,7 @@ func Test_verifyAnchorURIsIndependently(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := verifyAnchorURIs(tt.args.ctx, tt.args.uris, tt.args.expectedValue)
+			err := newTestVerifier(t, true).verifyAnchorURIs(tt.args.ctx, Result{}, tt.args.uris, tt.args.expectedValue)
 
 			if err != nil {
 				log.Error(err)
@@ -279,7 +401,8 @@ func Test_verifyBitcoinBlockMerkleRoot(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := verifyBitcoinBlockMerkleRoot(tt.args.ctx, tt.args.blockHeight, tt.args.expectedValue)
+			err := newTestVerifier(t, false).verifyBitcoinBlockMerkleRoot(tt.args.ctx, Result{}, tt.args.blockHeight, tt.args.expectedValue,
+				defaultEndpoints[btcAnchorBranchType])
 
 			if err != nil {
 				log.Error(err)
@@ -315,7 +438,7 @@ func Test_verifyBitcoinTxOpReturn(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := verifyBtcTxnData(tt.args.ctx, tt.args.txID, tt.args.expectedValue, true); (err != nil) != tt.wantErr {
+			if err := newTestVerifier(t, false).verifyBtcTxnData(tt.args.ctx, Result{}, tt.args.txID, tt.args.expectedValue, defaultEndpoints["btc_mainnet"]); (err != nil) != tt.wantErr {
 				t.Errorf("verifyBitcoinTxOpReturn() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -325,7 +448,7 @@ func Test_verifyBitcoinTxOpReturn(t *testing.T) {
 func Test_verifyCalendarBranch(t *testing.T) {
 	type args struct {
 		ctx    context.Context
-		branch map[string]interface{}
+		branch *model.EvaluatedBranch
 	}
 	tests := []struct {
 		name    string
@@ -336,44 +459,44 @@ func Test_verifyCalendarBranch(t *testing.T) {
 			"Verify Calendar anchor branch",
 			args{
 				context.Background(),
-				map[string]interface{}{
-					"label": "cal_anchor_branch",
-					"anchors": []interface{}{
-						map[string]interface{}{
-							"type":      "cal",
-							"anchor_id": "985637",
-							"uris": []string{
+				&model.EvaluatedBranch{
+					Label: "cal_anchor_branch",
+					Anchors: []model.EvaluatedAnchor{
+						{
+							Type:     "cal",
+							AnchorID: "985637",
+							URIs: []string{
 								"https://a.chainpoint.org/calendar/985637/hash",
 							},
-							"expected_value": "4690932f928fb7f7ce6e6c49ee95851742231709360be28b7ce2af7b92cfa95b",
+							ExpectedValue: "4690932f928fb7f7ce6e6c49ee95851742231709360be28b7ce2af7b92cfa95b",
 						},
-						map[string]interface{}{
-							"type":      "cal",
-							"anchor_id": "985635",
-							"uris": []string{
+						{
+							Type:     "cal",
+							AnchorID: "985635",
+							URIs: []string{
 								"https://a.chainpoint.org/calendar/985635/hash",
 								"https://a.chainpoint.org/calendar/985635/hash",
 								"https://a.chainpoint.org/calendar/985635/hash",
 							},
-							"expected_value": "4690932f928fb7f7ce6e6c49ee95851742231709360be28b7ce2af7b92cfa95b",
+							ExpectedValue: "4690932f928fb7f7ce6e6c49ee95851742231709360be28b7ce2af7b92cfa95b",
 						},
-						map[string]interface{}{
-							"type":      "cal",
-							"anchor_id": "985635",
-							"uris": []string{
+						{
+							Type:     "cal",
+							AnchorID: "985635",
+							URIs: []string{
 								"https://a.chainpoint.org/calendar/985635/hash",
 							},
-							"expected_value": "4690932f928fb7f7ce6e6c49ee95851742231709360be28b7ce2af7b92cfa95b",
+							ExpectedValue: "4690932f928fb7f7ce6e6c49ee95851742231709360be28b7ce2af7b92cfa95b",
 						},
 					},
 				},
 			},
-			true,
+			false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := verifyBranch(tt.args.ctx, tt.args.branch, "cal_anchor_branch"); (err != nil) != tt.wantErr {
+			if err := newTestVerifier(t, false).verifyBranch(tt.args.ctx, []string{tt.args.branch.Label}, tt.args.branch); (err != nil) != tt.wantErr {
 				t.Errorf("verifyCalendarBranch() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -383,7 +506,7 @@ func Test_verifyCalendarBranch(t *testing.T) {
 func Test_verifyBitcoinBranch(t *testing.T) {
 	type args struct {
 		ctx    context.Context
-		branch map[string]interface{}
+		branch *model.EvaluatedBranch
 	}
 	tests := []struct {
 		name    string
@@ -394,20 +517,20 @@ func Test_verifyBitcoinBranch(t *testing.T) {
 			"Verify Bitcoin anchor branch",
 			args{
 				context.Background(),
-				map[string]interface{}{
-					"label": "btc_anchor_branch",
-					"anchors": []interface{}{
-						map[string]interface{}{
-							"type":      "btc",
-							"anchor_id": "503275",
-							"uris": []interface{}{
+				&model.EvaluatedBranch{
+					Label: "btc_anchor_branch",
+					Anchors: []model.EvaluatedAnchor{
+						{
+							Type:     "btc",
+							AnchorID: "503275",
+							URIs: []string{
 								"https://a.chainpoint.org/calendar/985814/data",
 							},
-							"expected_value": "c617f5faca34474bea7020d75c39cb8427a32145f9646586ecb9184002131ad9",
+							ExpectedValue: "c617f5faca34474bea7020d75c39cb8427a32145f9646586ecb9184002131ad9",
 						},
 					},
-					"opReturnValue": "267335262e21e7adb4220068b4b90b7ff066324935d7f61ceab2a64080b06b1b",
-					"btcTxId":       "ba3c8c3e547ed73471c28a69659373f3f0a3b726aab31cdecd14513d9c581f1e",
+					OpReturnValue: "267335262e21e7adb4220068b4b90b7ff066324935d7f61ceab2a64080b06b1b",
+					BtcTxID:       "ba3c8c3e547ed73471c28a69659373f3f0a3b726aab31cdecd14513d9c581f1e",
 				},
 			},
 			false,
@@ -415,7 +538,7 @@ func Test_verifyBitcoinBranch(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := verifyBitcoinBranch(tt.args.ctx, tt.args.branch); (err != nil) != tt.wantErr {
+			if err := newTestVerifier(t, false).verifyBitcoinBranch(tt.args.ctx, []string{tt.args.branch.Label}, tt.args.branch); (err != nil) != tt.wantErr {
 				t.Errorf("verifyBitcoinBranch() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -425,7 +548,7 @@ func Test_verifyBitcoinBranch(t *testing.T) {
 func TestVerify(t *testing.T) {
 	type args struct {
 		ctx            context.Context
-		evaluatedProof interface{}
+		evaluatedProof *model.EvaluatedProof
 	}
 	tests := []struct {
 		name    string
@@ -436,7 +559,7 @@ func TestVerify(t *testing.T) {
 			"Verify evaluated Chainpoint v3 Proof - evaluated_proof1.json",
 			args{
 				context.Background(),
-				testutil.LoadJSON(t, "evaluated_proof1.json"),
+				testutil.LoadEvaluatedProof(t, "evaluated_proof1.json"),
 			},
 			false,
 		},
@@ -444,7 +567,7 @@ func TestVerify(t *testing.T) {
 			"Verify evaluated Chainpoint v3 Proof - evaluated_proof2.json",
 			args{
 				context.Background(),
-				testutil.LoadJSON(t, "evaluated_proof2.json"),
+				testutil.LoadEvaluatedProof(t, "evaluated_proof2.json"),
 			},
 			false,
 		},
@@ -452,7 +575,7 @@ func TestVerify(t *testing.T) {
 			"Verify evaluated Chainpoint v3 Proof - evaluated_proof3.json",
 			args{
 				context.Background(),
-				testutil.LoadJSON(t, "evaluated_proof3.json"),
+				testutil.LoadEvaluatedProof(t, "evaluated_proof3.json"),
 			},
 			false,
 		},
@@ -462,9 +585,178 @@ func TestVerify(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel() // run subtest in parallel
 
-			if err := Verify(tt.args.ctx, tt.args.evaluatedProof); (err != nil) != tt.wantErr {
+			if _, err := Verify(tt.args.ctx, newTestConfig(t, false), tt.args.evaluatedProof); (err != nil) != tt.wantErr {
 				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+// newTestEthTx signs a test Ethereum transaction, which is included in a block with the given
+// receipt status and the given header at the block number
+func newTestEthTx(t *testing.T, to common.Address, receiptStatus uint64, header *types.Header) (
+	*types.Transaction, *ethTx, common.Address) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := types.SignTx(
+		types.NewTransaction(0, to, big.NewInt(0), 21000, big.NewInt(1), []byte{0xab, 0xcd}),
+		types.NewEIP155Signer(big.NewInt(4)),
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canonical := &types.Header{Number: big.NewInt(100), Time: 1516080000}
+
+	tx, err := newEthTx(signed, &types.Receipt{
+		Status:      receiptStatus,
+		BlockHash:   canonical.Hash(),
+		BlockNumber: canonical.Number,
+	}, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed, tx, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// newTestEthEvidence creates the raw evidence of the given signed transaction, whose receipt has
+// the given status in the canonical block 100
+func newTestEthEvidence(t *testing.T, signed *types.Transaction, receiptStatus uint64) *rawEvidence {
+	header := &types.Header{Number: big.NewInt(100), Time: 1516080000, Difficulty: big.NewInt(0)}
+	receipt := &types.Receipt{
+		Status:      receiptStatus,
+		Logs:        []*types.Log{},
+		TxHash:      signed.Hash(),
+		BlockHash:   header.Hash(),
+		BlockNumber: header.Number,
+	}
+
+	r := newRawEvidence(ethTxFormat)
+
+	for _, v := range []interface{}{signed, receipt, header} {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r.Responses = append(r.Responses, raw)
+	}
+
+	return r
+}
+
+func Test_ethTxCheckAddresses(t *testing.T) {
+	recipient := common.HexToAddress("0x2f3ca2a1d6de4a3b2ef9c4e3e8dd5ff35b8e1b7a")
+	header := &types.Header{Number: big.NewInt(100), Time: 1516080000}
+	signed, tx, sender := newTestEthTx(t, recipient, types.ReceiptStatusSuccessful, header)
+
+	if tx.From != sender || tx.Data != "abcd" || tx.BlockTime.Unix() != 1516080000 {
+		t.Fatalf("newEthTx() = %+v, want sender %s", tx, sender.Hex())
+	}
+
+	tests := []struct {
+		name    string
+		ep      Endpoint
+		wantErr error
+	}{
+		{
+			"No allowlists",
+			Endpoint{},
+			nil,
+		},
+		{
+			"Trusted sender and recipient",
+			Endpoint{
+				Senders:    []string{"0x0000000000000000000000000000000000000001", strings.ToLower(sender.Hex())},
+				Recipients: []string{recipient.Hex()},
+			},
+			nil,
+		},
+		{
+			"Untrusted sender",
+			Endpoint{Senders: []string{recipient.Hex()}},
+			status.ErrAnchorUntrusted,
+		},
+		{
+			"Untrusted recipient",
+			Endpoint{Senders: []string{sender.Hex()}, Recipients: []string{sender.Hex()}},
+			status.ErrAnchorUntrusted,
+		},
+		{
+			"Invalid address",
+			Endpoint{Senders: []string{"provendb"}},
+			status.ErrAnchorUntrusted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tx.checkAddresses(signed.Hash().Hex(), tt.ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("checkAddresses() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil && status.Of(err).Status != status.VerificationStatusFalsified {
+				t.Errorf("checkAddresses() status = %s, want %s", status.Of(err).Status, status.VerificationStatusFalsified)
+			}
+		})
+	}
+}
+
+func Test_ethTxCheckInclusion(t *testing.T) {
+	recipient := common.HexToAddress("0x2f3ca2a1d6de4a3b2ef9c4e3e8dd5ff35b8e1b7a")
+
+	tests := []struct {
+		name          string
+		receiptStatus uint64
+		header        *types.Header
+		wantErr       error
+		wantStatus    status.VerificationStatus
+	}{
+		{
+			"Succeeded in canonical block",
+			types.ReceiptStatusSuccessful,
+			&types.Header{Number: big.NewInt(100), Time: 1516080000},
+			nil,
+			status.VerificationStatusVerified,
+		},
+		{
+			"Failed",
+			types.ReceiptStatusFailed,
+			&types.Header{Number: big.NewInt(100), Time: 1516080000},
+			status.ErrAnchorTxFailed,
+			status.VerificationStatusFalsified,
+		},
+		{
+			"Reorganized block",
+			types.ReceiptStatusSuccessful,
+			&types.Header{Number: big.NewInt(100), Time: 1516080012},
+			status.ErrAnchorNotCanonical,
+			status.VerificationStatusUnverifiable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, tx, _ := newTestEthTx(t, recipient, tt.receiptStatus, tt.header)
+
+			if tx.BlockNumber != 100 {
+				t.Errorf("newEthTx() block number = %d, want 100", tx.BlockNumber)
+			}
+
+			err := tx.checkInclusion(signed.Hash().Hex())
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("checkInclusion() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil && status.Of(err).Status != tt.wantStatus {
+				t.Errorf("checkInclusion() status = %s, want %s", status.Of(err).Status, tt.wantStatus)
+			}
+		})
+	}
+}
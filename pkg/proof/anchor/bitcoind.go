@@ -0,0 +1,421 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T21:05:52+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T21:05:52+11:00
+ */
+
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	btcHeaderSize = 80
+	opReturn      = 0x6a
+	opPushData1   = 0x4c
+	opPushData2   = 0x4d
+)
+
+// bitcoind is the JSON-RPC backend of a Bitcoin Core node
+type bitcoind struct {
+	ep Endpoint
+	// uri is the endpoint URL without credentials
+	uri string
+}
+
+func newBitcoind(ep Endpoint) (*bitcoind, error) {
+	u, err := neturl.Parse(ep.resolvedURL())
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitcoind endpoint: %s", err)
+	}
+
+	u.User = nil
+
+	return &bitcoind{
+		ep:  ep,
+		uri: u.String(),
+	}, nil
+}
+
+// call calls the given JSON-RPC method and unmarshals its result
+func (b *bitcoind) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0",
+		"id":      "provendb-verify",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.ep.resolvedURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if b.ep.Token != "" {
+		user, password := b.ep.Token, ""
+
+		if i := strings.Index(b.ep.Token, ":"); i >= 0 {
+			user, password = b.ep.Token[:i], b.ep.Token[i+1:]
+		}
+
+		req.SetBasicAuth(user, password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	// bitcoind responds RPC errors with a non-200 status code as well
+	err = json.NewDecoder(resp.Body).Decode(&rpcResp)
+	if err != nil {
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("got %s from %s", resp.Status, b.uri)
+		}
+
+		return fmt.Errorf("cannot decode `%s` response from %s: %s", method, b.uri, err)
+	}
+
+	if rpcResp.Error != nil {
+		return fmt.Errorf("`%s` failed on %s: %s (%d)", method, b.uri, rpcResp.Error.Message, rpcResp.Error.Code)
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+func (b *bitcoind) txURI(txID string) string {
+	return b.uri + "#getrawtransaction/" + txID
+}
+
+func (b *bitcoind) tx(ctx context.Context, txID string) (*rawEvidence, error) {
+	var tx json.RawMessage
+
+	err := b.call(ctx, "getrawtransaction", &tx, txID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRawEvidence(bitcoindTxFormat, tx), nil
+}
+
+func (b *bitcoind) blockURI(height string) string {
+	return b.uri + "#getblockheader/" + height
+}
+
+func (b *bitcoind) block(ctx context.Context, height string) (*rawEvidence, error) {
+	h, err := strconv.ParseInt(height, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Bitcoin block height `%s`: %s", height, err)
+	}
+
+	var hash, headerHex string
+
+	err = b.call(ctx, "getblockhash", &hash, h)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.call(ctx, "getblockheader", &headerHex, hash, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRawEvidence(bitcoindBlockFormat, rawText(hash), rawText(headerHex)), nil
+}
+
+func (b *bitcoind) txConfirmations(ctx context.Context, txID string) (int64, error) {
+	var tx struct {
+		// Confirmations is missing when the transaction is in the mempool
+		Confirmations int64 `json:"confirmations"`
+	}
+
+	err := b.call(ctx, "getrawtransaction", &tx, txID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	return tx.Confirmations, nil
+}
+
+func (b *bitcoind) tipURI() string {
+	return b.uri + "#getblockcount"
+}
+
+func (b *bitcoind) tipHeight(ctx context.Context) (int64, error) {
+	var height int64
+
+	err := b.call(ctx, "getblockcount", &height)
+	if err != nil {
+		return 0, err
+	}
+
+	return height, nil
+}
+
+// btcHash returns the double SHA-256 of the given data in hex with the byte order reversed, which is
+// how Bitcoin displays a block or transaction hash
+func btcHash(data []byte) string {
+	h := sha256d(data)
+	return hex.EncodeToString(reverseBytes(h[:]))
+}
+
+// parseBtcHeader parses the given 80-byte block header in hex, which must hash to the given block
+// hash
+func parseBtcHeader(hash, headerHex string) (*btcBlock, error) {
+	header, err := hex.DecodeString(headerHex)
+	if err != nil || len(header) != btcHeaderSize {
+		return nil, fmt.Errorf("invalid Bitcoin block header of block `%s`", hash)
+	}
+
+	if got := btcHash(header); got != hash {
+		return nil, fmt.Errorf("Bitcoin block header of block `%s` hashes to `%s`", hash, got)
+	}
+
+	return &btcBlock{
+		MerkleRoot: btcHeaderMerkleRoot(header),
+		Time:       btcHeaderTime(header),
+	}, nil
+}
+
+// btcHeaderTime returns the block time of the given 80-byte block header
+func btcHeaderTime(header []byte) time.Time {
+	return time.Unix(int64(binary.LittleEndian.Uint32(header[68:72])), 0).UTC()
+}
+
+// btcHeaderMerkleRoot returns the merkle root of the given 80-byte block header in the display
+// byte order
+func btcHeaderMerkleRoot(header []byte) string {
+	return hex.EncodeToString(reverseBytes(header[36:68]))
+}
+
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+
+	return r
+}
+
+// btcTxOpReturn decodes the data pushed by the first OP_RETURN output of the given raw Bitcoin
+// transaction. It is empty when there is no such output
+func btcTxOpReturn(raw []byte) ([]byte, error) {
+	r := &btcReader{data: raw}
+
+	// version
+	r.skip(4)
+
+	if len(raw) > 6 && raw[4] == 0 && raw[5] != 0 {
+		// segwit marker and flag
+		r.skip(2)
+	}
+
+	nIn := r.varInt()
+	for i := uint64(0); i < nIn && r.err == nil; i++ {
+		// previous output, script and sequence
+		r.skip(36)
+		r.skip(int(r.varInt()))
+		r.skip(4)
+	}
+
+	nOut := r.varInt()
+	for i := uint64(0); i < nOut && r.err == nil; i++ {
+		// value
+		r.skip(8)
+		script := r.bytes(int(r.varInt()))
+
+		if r.err == nil && len(script) > 0 && script[0] == opReturn {
+			return opReturnData(script)
+		}
+	}
+
+	return nil, r.err
+}
+
+// btcTxID returns the ID of the given raw Bitcoin transaction, which is the hash of its
+// serialization without the segwit marker, flag and witnesses
+func btcTxID(raw []byte) (string, error) {
+	r := &btcReader{data: raw}
+	segwit := len(raw) > 6 && raw[4] == 0 && raw[5] != 0
+
+	// version
+	r.skip(4)
+
+	if segwit {
+		// segwit marker and flag
+		r.skip(2)
+	}
+
+	start := r.pos
+
+	nIn := r.varInt()
+	for i := uint64(0); i < nIn && r.err == nil; i++ {
+		// previous output, script and sequence
+		r.skip(36)
+		r.skip(int(r.varInt()))
+		r.skip(4)
+	}
+
+	nOut := r.varInt()
+	for i := uint64(0); i < nOut && r.err == nil; i++ {
+		// value and script
+		r.skip(8)
+		r.skip(int(r.varInt()))
+	}
+
+	end := r.pos
+
+	if segwit {
+		for i := uint64(0); i < nIn && r.err == nil; i++ {
+			nItems := r.varInt()
+			for j := uint64(0); j < nItems && r.err == nil; j++ {
+				r.skip(int(r.varInt()))
+			}
+		}
+	}
+
+	lockTime := r.bytes(4)
+
+	if r.err != nil {
+		return "", r.err
+	}
+
+	if r.pos != len(raw) {
+		return "", errors.New("unexpected data after lock time")
+	}
+
+	stripped := make([]byte, 0, 4+end-start+4)
+	stripped = append(stripped, raw[:4]...)
+	stripped = append(stripped, raw[start:end]...)
+	stripped = append(stripped, lockTime...)
+
+	return btcHash(stripped), nil
+}
+
+// opReturnData gets the data pushed by the given OP_RETURN script
+func opReturnData(script []byte) ([]byte, error) {
+	r := &btcReader{data: script, pos: 1}
+
+	if len(script) == 1 {
+		return []byte{}, nil
+	}
+
+	var n int
+
+	switch op := r.bytes(1); {
+	case r.err != nil:
+	case op[0] > 0 && op[0] < opPushData1:
+		n = int(op[0])
+	case op[0] == opPushData1:
+		if b := r.bytes(1); r.err == nil {
+			n = int(b[0])
+		}
+	case op[0] == opPushData2:
+		if b := r.bytes(2); r.err == nil {
+			n = int(binary.LittleEndian.Uint16(b))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported OP_RETURN push opcode 0x%02x", op[0])
+	}
+
+	data := r.bytes(n)
+	return data, r.err
+}
+
+// btcReader reads the Bitcoin wire format. Once failed, it keeps its first error
+type btcReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *btcReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+
+	return b
+}
+
+func (r *btcReader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *btcReader) varInt() uint64 {
+	b := r.bytes(1)
+	if r.err != nil {
+		return 0
+	}
+
+	switch b[0] {
+	case 0xfd:
+		if b := r.bytes(2); r.err == nil {
+			return uint64(binary.LittleEndian.Uint16(b))
+		}
+	case 0xfe:
+		if b := r.bytes(4); r.err == nil {
+			return uint64(binary.LittleEndian.Uint32(b))
+		}
+	case 0xff:
+		if b := r.bytes(8); r.err == nil {
+			return binary.LittleEndian.Uint64(b)
+		}
+	default:
+		return uint64(b[0])
+	}
+
+	return 0
+}
@@ -0,0 +1,384 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T21:28:14+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T21:28:14+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+const (
+	testOpReturnValue = "267335262e21e7adb4220068b4b90b7ff066324935d7f61ceab2a64080b06b1b"
+	// testBlockTime is the block time of the test transactions
+	testBlockTime = 1516080000
+	// testHeaderTime is the block time in the test headers, which is `a0b1c2d3` in little endian
+	testHeaderTime = 0xd3c2b1a0
+)
+
+// testTxInputs are the inputs of a raw test transaction
+var testTxInputs = "01" + strings.Repeat("ab", 32) + "00000000" + "00" + "ffffffff"
+
+// testForgedTxID is the ID of a transaction that a test node forges using another transaction
+var testForgedTxID = strings.Repeat("ef", 32)
+
+// testRawTx builds a raw Bitcoin transaction with an OP_RETURN output after a payment output. The
+// segwit one is of version 2, so it has a different ID
+func testRawTx(segwit bool) string {
+	tx := "01000000"
+
+	if segwit {
+		tx = "02000000" + "0001"
+	}
+
+	tx += testTxInputs
+	// a payment output and an OP_RETURN output
+	tx += "02" +
+		"1027000000000000" + "19" + "76a914" + strings.Repeat("cd", 20) + "88ac" +
+		"0000000000000000" + "22" + "6a20" + testOpReturnValue
+
+	if segwit {
+		// witness of the input
+		tx += "01" + "02" + "abcd"
+	}
+
+	return tx + "00000000"
+}
+
+// testTxID returns the ID of the raw test transaction
+func testTxID(t *testing.T, segwit bool) string {
+	id, err := btcTxID(mustDecodeHex(t, testRawTx(segwit)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+// testBlockHeader returns an 80-byte test block header in hex, whose merkle root is the reversed
+// `testOpReturnValue`, along with its block hash
+func testBlockHeader(t *testing.T) (header, blockHash string) {
+	header = "00000020" + strings.Repeat("00", 32) +
+		hex.EncodeToString(reverseBytes(mustDecodeHex(t, testOpReturnValue))) +
+		"a0b1c2d3" + "ffff001d" + "01020304"
+
+	return header, btcHash(mustDecodeHex(t, header))
+}
+
+func newTestBitcoind(t *testing.T) *httptest.Server {
+	header, blockHash := testBlockHeader(t)
+	legacyTxID, segwitTxID := testTxID(t, false), testTxID(t, true)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result interface{}
+
+		switch req.Method {
+		case "getrawtransaction":
+			if req.Params[1] != true {
+				t.Errorf("getrawtransaction params = %v", req.Params)
+			}
+
+			tx := map[string]interface{}{
+				"blocktime":     testBlockTime,
+				"confirmations": 3,
+			}
+
+			switch req.Params[0] {
+			case legacyTxID, testForgedTxID:
+				tx["hex"] = testRawTx(false)
+			case segwitTxID:
+				tx["hex"] = testRawTx(true)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"result": nil,
+					"error": map[string]interface{}{
+						"code":    -5,
+						"message": "No such mempool or blockchain transaction",
+					},
+				})
+				return
+			}
+
+			result = tx
+		case "getblockcount":
+			result = 503277
+		case "getblockhash":
+			result = blockHash
+		case "getblockheader":
+			if req.Params[0] != blockHash || req.Params[1] != false {
+				t.Errorf("getblockheader params = %v", req.Params)
+			}
+
+			result = header
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"result": result,
+			"error":  nil,
+		})
+	}))
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestBitcoind(t *testing.T) {
+	ts := newTestBitcoind(t)
+	defer ts.Close()
+
+	ep := Endpoint{URL: ts.URL, Token: "user:pass", Provider: ProviderBitcoind}
+	legacyTxID, segwitTxID := testTxID(t, false), testTxID(t, true)
+
+	tests := []struct {
+		name          string
+		txID          string
+		token         string
+		expectedValue string
+		wantErr       error
+	}{
+		{
+			"Verify legacy transaction",
+			legacyTxID,
+			ep.Token,
+			testOpReturnValue,
+			nil,
+		},
+		{
+			"Verify segwit transaction",
+			segwitTxID,
+			ep.Token,
+			testOpReturnValue,
+			nil,
+		},
+		{
+			"Falsify transaction",
+			legacyTxID,
+			ep.Token,
+			strings.Repeat("00", 32),
+			status.ErrAnchorValueMismatch,
+		},
+		{
+			"Missing transaction",
+			"missing",
+			ep.Token,
+			testOpReturnValue,
+			status.ErrAnchorUnreachable,
+		},
+		{
+			"Forged transaction",
+			testForgedTxID,
+			ep.Token,
+			testOpReturnValue,
+			status.ErrAnchorUnreachable,
+		},
+		{
+			"Wrong credentials",
+			legacyTxID,
+			"user:wrong",
+			testOpReturnValue,
+			status.ErrAnchorUnreachable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := ep
+			ep.Token = tt.token
+
+			v := newTestVerifier(t, true)
+			err := v.verifyBtcTxnData(context.Background(), Result{}, tt.txID, tt.expectedValue, ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyBtcTxnData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			r := v.results[0]
+
+			if strings.Contains(r.URI, "pass") || r.URI != ts.URL+"#getrawtransaction/"+tt.txID {
+				t.Errorf("verifyBtcTxnData() recorded URI %s", r.URI)
+			}
+
+			if tt.wantErr == nil && (r.AnchoredAt == nil || r.AnchoredAt.Unix() != testBlockTime) {
+				t.Errorf("verifyBtcTxnData() recorded anchoring time %v", r.AnchoredAt)
+			}
+		})
+	}
+
+	t.Run("Verify block merkle root", func(t *testing.T) {
+		v := newTestVerifier(t, true)
+
+		err := v.verifyBitcoinBlockMerkleRoot(context.Background(), Result{}, "503275", testOpReturnValue, ep)
+		if err != nil {
+			t.Fatalf("verifyBitcoinBlockMerkleRoot() error = %v", err)
+		}
+
+		if r := v.results[0]; r.AnchoredAt == nil || r.AnchoredAt.Unix() != testHeaderTime {
+			t.Errorf("verifyBitcoinBlockMerkleRoot() recorded anchoring time %v", r.AnchoredAt)
+		}
+	})
+
+	t.Run("Check confirmations", func(t *testing.T) {
+		ep := ep
+		ep.MinConfirmations = int64Ptr(3)
+		v := newTestVerifier(t, true)
+
+		err := v.verifyBtcTxnData(context.Background(), Result{}, legacyTxID, testOpReturnValue, ep)
+		if err != nil {
+			t.Fatalf("verifyBtcTxnData() error = %v", err)
+		}
+
+		err = v.verifyBitcoinBlockMerkleRoot(context.Background(), Result{}, "503275", testOpReturnValue, ep)
+		if err != nil {
+			t.Fatalf("verifyBitcoinBlockMerkleRoot() error = %v", err)
+		}
+
+		ep.MinConfirmations = int64Ptr(4)
+
+		err = v.verifyBtcTxnData(context.Background(), Result{}, legacyTxID, testOpReturnValue, ep)
+		if !errors.Is(err, status.ErrInsufficientConfirmations) {
+			t.Fatalf("verifyBtcTxnData() error = %v, want %v", err, status.ErrInsufficientConfirmations)
+		}
+	})
+}
+
+func Test_btcTxOpReturn(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{
+			"No OP_RETURN output",
+			"01000000" + testTxInputs + "01" + "1027000000000000" + "01" + "51" + "00000000",
+			"",
+			false,
+		},
+		{
+			"PUSHDATA1",
+			"01000000" + testTxInputs + "01" + "0000000000000000" + "05" + "6a4c02abcd" + "00000000",
+			"abcd",
+			false,
+		},
+		{
+			"Truncated",
+			testRawTx(false)[:100],
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := btcTxOpReturn(mustDecodeHex(t, tt.raw))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("btcTxOpReturn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("btcTxOpReturn() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_btcTxID(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{
+			"Genesis coinbase transaction",
+			"01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d" +
+				"0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f" +
+				"66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0" +
+				"fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c38" +
+				"4df7ba0b8d578a4c702b6bf11d5fac00000000",
+			"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+			false,
+		},
+		{
+			"Segwit transaction without witnesses",
+			testRawTx(true),
+			btcHash(mustDecodeHex(t, "02000000"+testRawTx(false)[8:])),
+			false,
+		},
+		{
+			"Trailing data",
+			testRawTx(false) + "00",
+			"",
+			true,
+		},
+		{
+			"Truncated",
+			testRawTx(true)[:len(testRawTx(true))-10],
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := btcTxID(mustDecodeHex(t, tt.raw))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("btcTxID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("btcTxID() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
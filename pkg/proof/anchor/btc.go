@@ -0,0 +1,374 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T20:41:36+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T20:41:36+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/httputil"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+// Providers of a Bitcoin `Endpoint`
+const (
+	// ProviderBlockCypher is the BlockCypher API, which is the default
+	ProviderBlockCypher = "blockcypher"
+	// ProviderBitcoind is the JSON-RPC of a Bitcoin Core node, which requires `txindex=1`
+	ProviderBitcoind = "bitcoind"
+	// ProviderEsplora is the Esplora REST API, such as the ones of Blockstream and mempool.space
+	ProviderEsplora = "esplora"
+)
+
+// Formats of the raw evidence of Bitcoin transactions and blocks, which are named after their
+// providers
+const (
+	// blockCypherTxFormat is the BlockCypher transaction body, which includes the raw transaction
+	blockCypherTxFormat = ProviderBlockCypher + "/tx"
+	// blockCypherBlockFormat is the BlockCypher block body, which has the fields of the block header
+	blockCypherBlockFormat = ProviderBlockCypher + "/block"
+	// bitcoindTxFormat is the verbose `getrawtransaction` result
+	bitcoindTxFormat = ProviderBitcoind + "/tx"
+	// bitcoindBlockFormat is the block hash and the raw block header
+	bitcoindBlockFormat = ProviderBitcoind + "/block"
+	// esploraTxFormat is the raw transaction and the transaction status body
+	esploraTxFormat = ProviderEsplora + "/tx"
+	// esploraBlockFormat is the block hash and the raw block header
+	esploraBlockFormat = ProviderEsplora + "/block"
+)
+
+// btcBackend looks up Bitcoin transactions and blocks
+type btcBackend interface {
+	// txURI returns the location of the given transaction, which is also the source URL of its
+	// evidence
+	txURI(txID string) string
+	// tx gets the raw evidence of the given transaction
+	tx(ctx context.Context, txID string) (*rawEvidence, error)
+	// blockURI returns the location of the block at the given height, which is also the source URL
+	// of its evidence
+	blockURI(height string) string
+	// block gets the raw evidence of the block at the given height
+	block(ctx context.Context, height string) (*rawEvidence, error)
+	// txConfirmations gets the number of confirmations of the given transaction, which is 0 when it
+	// is unconfirmed
+	txConfirmations(ctx context.Context, txID string) (int64, error)
+	// tipURI returns the location of the chain tip
+	tipURI() string
+	// tipHeight gets the height of the chain tip
+	tipHeight(ctx context.Context) (int64, error)
+}
+
+// btcTx represents a Bitcoin transaction
+type btcTx struct {
+	// OpReturn is the OP_RETURN data in hex
+	OpReturn string `json:"opReturn"`
+	// BlockTime is the time of the block that includes the transaction, which is zero when the
+	// transaction is unconfirmed
+	BlockTime time.Time `json:"blockTime"`
+}
+
+// btcBlock represents a Bitcoin block
+type btcBlock struct {
+	// MerkleRoot is the merkle root in hex
+	MerkleRoot string `json:"merkleRoot"`
+	// Time is the block time
+	Time time.Time `json:"time"`
+}
+
+// parse parses the raw evidence of the given transaction from any provider, whose raw transaction
+// must hash to the transaction ID
+func (t *btcTx) parse(r *rawEvidence, txID string) error {
+	var (
+		txHex string
+		// blockTime is 0 when the transaction is unconfirmed
+		blockTime int64
+	)
+
+	switch r.Format {
+	case blockCypherTxFormat:
+		var tx struct {
+			Error     string    `json:"error"`
+			Hex       string    `json:"hex"`
+			Confirmed time.Time `json:"confirmed"`
+		}
+
+		err := r.response(0, &tx)
+		if err != nil {
+			return err
+		}
+
+		if tx.Error != "" {
+			return errors.New(tx.Error)
+		}
+
+		txHex = tx.Hex
+
+		if !tx.Confirmed.IsZero() {
+			blockTime = tx.Confirmed.Unix()
+		}
+	case bitcoindTxFormat:
+		var tx struct {
+			Hex string `json:"hex"`
+			// BlockTime is missing when the transaction is in the mempool
+			BlockTime int64 `json:"blocktime"`
+		}
+
+		err := r.response(0, &tx)
+		if err != nil {
+			return err
+		}
+
+		txHex, blockTime = tx.Hex, tx.BlockTime
+	case esploraTxFormat:
+		var txStatus struct {
+			BlockTime int64 `json:"block_time"`
+		}
+
+		err := r.response(0, &txHex)
+		if err != nil {
+			return err
+		}
+
+		err = r.response(1, &txStatus)
+		if err != nil {
+			return err
+		}
+
+		blockTime = txStatus.BlockTime
+	default:
+		return r.errUnsupported()
+	}
+
+	raw, err := hex.DecodeString(txHex)
+	if err != nil {
+		return fmt.Errorf("invalid Bitcoin transaction `%s`: %s", txID, err)
+	}
+
+	// the provider must not be trusted to return the requested transaction
+	id, err := btcTxID(raw)
+	if err != nil {
+		return fmt.Errorf("invalid Bitcoin transaction `%s`: %s", txID, err)
+	}
+
+	if !strings.EqualFold(id, txID) {
+		return fmt.Errorf("Bitcoin transaction `%s` hashes to `%s`", txID, id)
+	}
+
+	data, err := btcTxOpReturn(raw)
+	if err != nil {
+		return fmt.Errorf("invalid Bitcoin transaction `%s`: %s", txID, err)
+	}
+
+	*t = btcTx{
+		OpReturn: hex.EncodeToString(data),
+	}
+
+	if blockTime != 0 {
+		t.BlockTime = time.Unix(blockTime, 0).UTC()
+	}
+
+	return nil
+}
+
+// parse parses the raw evidence of a block from any provider, whose block header must hash to the
+// block hash
+func (b *btcBlock) parse(r *rawEvidence) error {
+	var hash, headerHex string
+
+	switch r.Format {
+	case blockCypherBlockFormat:
+		var block struct {
+			Error      string    `json:"error"`
+			Hash       string    `json:"hash"`
+			Version    uint32    `json:"ver"`
+			PrevBlock  string    `json:"prev_block"`
+			MerkleRoot string    `json:"mrkl_root"`
+			Time       time.Time `json:"time"`
+			Bits       uint32    `json:"bits"`
+			Nonce      uint32    `json:"nonce"`
+		}
+
+		err := r.response(0, &block)
+		if err != nil {
+			return err
+		}
+
+		if block.Error != "" {
+			return errors.New(block.Error)
+		}
+
+		prevBlock, err := hex.DecodeString(block.PrevBlock)
+		if err != nil || len(prevBlock) != 32 {
+			return fmt.Errorf("invalid previous block of Bitcoin block `%s`", block.Hash)
+		}
+
+		merkleRoot, err := hex.DecodeString(block.MerkleRoot)
+		if err != nil || len(merkleRoot) != 32 {
+			return fmt.Errorf("invalid merkle root of Bitcoin block `%s`", block.Hash)
+		}
+
+		// BlockCypher has no raw header, so it is serialized from the fields to check the hash
+		header := make([]byte, btcHeaderSize)
+		binary.LittleEndian.PutUint32(header[0:4], block.Version)
+		copy(header[4:36], reverseBytes(prevBlock))
+		copy(header[36:68], reverseBytes(merkleRoot))
+		binary.LittleEndian.PutUint32(header[68:72], uint32(block.Time.Unix()))
+		binary.LittleEndian.PutUint32(header[72:76], block.Bits)
+		binary.LittleEndian.PutUint32(header[76:80], block.Nonce)
+
+		hash, headerHex = block.Hash, hex.EncodeToString(header)
+	case bitcoindBlockFormat, esploraBlockFormat:
+		err := r.response(0, &hash)
+		if err != nil {
+			return err
+		}
+
+		err = r.response(1, &headerHex)
+		if err != nil {
+			return err
+		}
+	default:
+		return r.errUnsupported()
+	}
+
+	block, err := parseBtcHeader(hash, headerHex)
+	if err != nil {
+		return err
+	}
+
+	*b = *block
+
+	return nil
+}
+
+// newBtcBackend creates the Bitcoin backend of the given endpoint
+func newBtcBackend(ep Endpoint) (btcBackend, error) {
+	if ep.URL == "" {
+		// the default of an alternate endpoint, which is mapped to Blockstream by Esplora
+		ep.URL = blockCypherURL
+	}
+
+	switch ep.Provider {
+	case "", ProviderBlockCypher:
+		return &blockCypher{ep}, nil
+	case ProviderBitcoind:
+		return newBitcoind(ep)
+	case ProviderEsplora:
+		return newEsplora(ep), nil
+	}
+
+	return nil, status.NewCodedError(
+		status.VerificationStatusUnverifiable,
+		status.CodeAnchorUnsupported,
+		fmt.Errorf("Bitcoin endpoint provider `%s` is not supported", ep.Provider),
+	)
+}
+
+// confirmations returns the number of confirmations of the block at the given height
+func confirmations(tipHeight, height int64) int64 {
+	return tipHeight - height + 1
+}
+
+// blockCypher is the BlockCypher API backend
+type blockCypher struct {
+	ep Endpoint
+}
+
+func (b *blockCypher) txURI(txID string) string {
+	return b.ep.withQuery(fmt.Sprintf("%s/%s/txs/%s", b.ep.resolvedURL(), b.ep.Network, txID),
+		neturl.Values{"includeHex": {"true"}})
+}
+
+func (b *blockCypher) tx(ctx context.Context, txID string) (*rawEvidence, error) {
+	return b.get(ctx, b.txURI(txID), blockCypherTxFormat)
+}
+
+func (b *blockCypher) blockURI(height string) string {
+	return b.ep.withQuery(fmt.Sprintf("%s/%s/blocks/%s", b.ep.resolvedURL(), b.ep.Network, height),
+		neturl.Values{"txstart": {"1"}, "limit": {"1"}})
+}
+
+func (b *blockCypher) block(ctx context.Context, height string) (*rawEvidence, error) {
+	return b.get(ctx, b.blockURI(height), blockCypherBlockFormat)
+}
+
+// get gets the body of the given URL as the raw evidence of the given format
+func (b *blockCypher) get(ctx context.Context, url, format string) (*rawEvidence, error) {
+	var body json.RawMessage
+
+	err := httputil.UnmarshalHTTPGetJSON(ctx, url, &body)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRawEvidence(format, body), nil
+}
+
+func (b *blockCypher) txConfirmations(ctx context.Context, txID string) (int64, error) {
+	var tx struct {
+		Error         string `json:"error"`
+		Confirmations int64  `json:"confirmations"`
+	}
+
+	err := httputil.UnmarshalHTTPGetJSON(ctx, b.txURI(txID), &tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if tx.Error != "" {
+		return 0, errors.New(tx.Error)
+	}
+
+	return tx.Confirmations, nil
+}
+
+func (b *blockCypher) tipURI() string {
+	return b.ep.withQuery(fmt.Sprintf("%s/%s", b.ep.resolvedURL(), b.ep.Network), neturl.Values{})
+}
+
+func (b *blockCypher) tipHeight(ctx context.Context) (int64, error) {
+	var chain struct {
+		Error  string `json:"error"`
+		Height int64  `json:"height"`
+	}
+
+	err := httputil.UnmarshalHTTPGetJSON(ctx, b.tipURI(), &chain)
+	if err != nil {
+		return 0, err
+	}
+
+	if chain.Error != "" {
+		return 0, errors.New(chain.Error)
+	}
+
+	return chain.Height, nil
+}
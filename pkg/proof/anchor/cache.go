@@ -0,0 +1,113 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T17:12:06+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T17:12:06+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"sync"
+)
+
+// LookupCache memoizes anchor lookups, such as the value returned by an anchor URI or the data of a
+// blockchain transaction, so they can be reused across verifications. Concurrent lookups of the
+// same key are made only once. Failed lookups are not cached, and the callers waiting on a lookup
+// that is cancelled by its own caller fetch it again. It is safe for concurrent use
+type LookupCache struct {
+	mu      sync.Mutex
+	entries map[string]*lookupEntry
+}
+
+type lookupEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	// canceled indicates whether the lookup failed as its caller's context was done
+	canceled bool
+}
+
+// NewLookupCache creates a new LookupCache
+func NewLookupCache() *LookupCache {
+	return &LookupCache{
+		entries: make(map[string]*lookupEntry),
+	}
+}
+
+// Len returns the number of cached lookups
+func (c *LookupCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// get gets the value of the given key, which is fetched using the given function when it is not
+// cached yet. The function must be bound to the given context
+func (c *LookupCache) get(ctx context.Context, key string, fetch func() (interface{}, error)) (
+	interface{}, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	for {
+		c.mu.Lock()
+
+		if e, ok := c.entries[key]; ok {
+			c.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-e.done:
+			}
+
+			if e.canceled {
+				// the lookup was cancelled by another caller, so fetch it again with this context
+				continue
+			}
+
+			return e.value, e.err
+		}
+
+		e := &lookupEntry{
+			done: make(chan struct{}),
+		}
+		c.entries[key] = e
+		c.mu.Unlock()
+
+		e.value, e.err = fetch()
+
+		if e.err != nil {
+			e.canceled = ctx.Err() != nil
+
+			// evict the failed lookup before releasing the waiters, so a retry won't see it again
+			c.mu.Lock()
+			delete(c.entries, key)
+			c.mu.Unlock()
+		}
+
+		close(e.done)
+
+		return e.value, e.err
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T17:12:06+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T17:12:06+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookupCache(t *testing.T) {
+	var (
+		c       = NewLookupCache()
+		fetches int32
+		wg      sync.WaitGroup
+		release = make(chan struct{})
+	)
+
+	fetch := func() (interface{}, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return "ff", nil
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v, err := c.get(context.Background(), "https://anchor/1", fetch)
+			if err != nil || v != "ff" {
+				t.Errorf("get() = %v, %v, want ff", v, err)
+			}
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("get() fetched %d times, want 1", n)
+	}
+
+	failed := errors.New("unreachable")
+
+	for i := 0; i < 2; i++ {
+		_, err := c.get(context.Background(), "https://anchor/2", func() (interface{}, error) {
+			atomic.AddInt32(&fetches, 1)
+			return nil, failed
+		})
+		if err != failed {
+			t.Errorf("get() error = %v, want %v", err, failed)
+		}
+	}
+
+	if n := atomic.LoadInt32(&fetches); n != 3 {
+		t.Errorf("get() fetched %d times, want 3 as failed lookups are not cached", n)
+	}
+
+	if n := c.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	waited := make(chan struct{})
+
+	go func() {
+		defer close(waited)
+
+		// wait until the first caller's lookup is in flight
+		<-started
+
+		v, err := c.get(context.Background(), "https://anchor/3", func() (interface{}, error) {
+			atomic.AddInt32(&fetches, 1)
+			return "ee", nil
+		})
+		if err != nil || v != "ee" {
+			t.Errorf("get() = %v, %v, want ee after the first caller is cancelled", v, err)
+		}
+	}()
+
+	_, err := c.get(ctx, "https://anchor/3", func() (interface{}, error) {
+		atomic.AddInt32(&fetches, 1)
+		close(started)
+		// give the second caller a chance to wait on this lookup before cancelling it
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("get() error = %v, want %v", err, context.Canceled)
+	}
+
+	<-waited
+
+	if n := atomic.LoadInt32(&fetches); n != 5 {
+		t.Errorf("get() fetched %d times, want 5 as cancelled lookups are fetched again", n)
+	}
+
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+
+	var nilCache *LookupCache
+
+	v, err := nilCache.get(context.Background(), "https://anchor/1", func() (interface{}, error) {
+		return "00", nil
+	})
+	if err != nil || v != "00" {
+		t.Errorf("get() = %v, %v, want 00 from a nil cache", v, err)
+	}
+}
@@ -0,0 +1,467 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T11:02:47+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T11:02:47+11:00
+ */
+
+package anchor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	envBCToken                   = "PROVENDB_VERIFY_BCTOKEN"
+	envVerifyAnchorIndependently = "PROVENDB_VERIFY_VERIFY_ANCHOR_INDEPENDENTLY"
+	envConfig                    = "PROVENDB_VERIFY_CONFIG"
+	envEndpointPrefix            = "PROVENDB_VERIFY_ENDPOINT_"
+	envMinConfirmationsSuffix    = "_MIN_CONFIRMATIONS"
+
+	tokenPlaceholder = "{token}"
+	blockCypherURL   = "https://api.blockcypher.com/v1/btc"
+
+	// defaultBtcMinConfirmations is the default minimum number of confirmations of the Bitcoin
+	// anchor types
+	defaultBtcMinConfirmations = 6
+)
+
+// bcToken is the default BlockCypher access token, which can be injected at build time using
+// `-ldflags "-X .../pkg/proof/anchor.bcToken=TOKEN"`
+var bcToken = ""
+
+// infuraToken is the default Infura project ID of the Ethereum anchor types, which can be injected
+// at build time using `-ldflags "-X .../pkg/proof/anchor.infuraToken=TOKEN"`
+var infuraToken = ""
+
+// Endpoint represents the blockchain API used to verify the anchors of an anchor type
+type Endpoint struct {
+	// URL is the API URL, such as the base URL of a Hedera mirror node, in which `{token}` is
+	// replaced with `Token`
+	URL string `json:"url,omitempty"`
+	// Token is the access token of the API. It is sent as the `token` query parameter to
+	// BlockCypher, and as the `USER:PASSWORD` basic authentication credentials to bitcoind
+	Token string `json:"token,omitempty"`
+	// Network is the blockchain network, such as `main` or `test3` for BlockCypher
+	Network string `json:"network,omitempty"`
+	// Provider is the kind of the API, such as `blockcypher` (default), `bitcoind` or `esplora` for
+	// a Bitcoin anchor type
+	Provider string `json:"provider,omitempty"`
+	// MinConfirmations is the minimum number of confirmations of an anchored transaction or block,
+	// below which the anchor is unverifiable. It is ignored by Hedera, whose transactions are final
+	// once they reach consensus. It is unset when nil, so an explicit zero can override a default
+	MinConfirmations *int64 `json:"minConfirmations,omitempty"`
+	// Senders are the trusted sender addresses of an anchoring Ethereum transaction. Any sender is
+	// trusted when it is empty
+	Senders []string `json:"senders,omitempty"`
+	// Recipients are the trusted recipient addresses of an anchoring Ethereum transaction. Any
+	// recipient is trusted when it is empty
+	Recipients []string `json:"recipients,omitempty"`
+	// Alternates are the other providers of the same network, such as an Esplora API along with
+	// BlockCypher, which are queried along with this one for a quorum. Their network defaults to
+	// this one's
+	Alternates []Endpoint `json:"alternates,omitempty"`
+	// Quorum is the minimum number of providers, among this one and `Alternates`, that must agree
+	// on the value of an anchored transaction or block. It defaults to a majority when it is nil or
+	// zero
+	Quorum *int64 `json:"quorum,omitempty"`
+}
+
+// resolvedURL returns the URL with its token filled in
+func (e Endpoint) resolvedURL() string {
+	return strings.Replace(e.URL, tokenPlaceholder, e.Token, -1)
+}
+
+// checkToken checks the endpoint has a token when its URL requires one
+func (e Endpoint) checkToken() error {
+	if e.Token == "" && strings.Contains(e.URL, tokenPlaceholder) {
+		return fmt.Errorf("no access token is configured for `%s`", e.URL)
+	}
+
+	return nil
+}
+
+// Validate checks the endpoint and its alternates, whose trusted addresses must be Ethereum addresses
+// in hex
+func (e Endpoint) Validate() error {
+	for _, f := range []struct {
+		kind      string
+		addresses []string
+	}{
+		{"sender", e.Senders},
+		{"recipient", e.Recipients},
+	} {
+		for _, a := range f.addresses {
+			if !common.IsHexAddress(a) {
+				return fmt.Errorf("invalid Ethereum %s address `%s`", f.kind, a)
+			}
+		}
+	}
+
+	for _, a := range e.Alternates {
+		err := a.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// int64Ptr returns a pointer to the given number
+func int64Ptr(n int64) *int64 {
+	return &n
+}
+
+// minConfirmations returns the minimum number of confirmations, which is zero when unset
+func (e Endpoint) minConfirmations() int64 {
+	if e.MinConfirmations == nil {
+		return 0
+	}
+
+	return *e.MinConfirmations
+}
+
+// merge overrides the endpoint with the set fields of the other, where strings and slices are set
+// when non-empty, and numbers are set when non-nil
+func (e Endpoint) merge(o Endpoint) Endpoint {
+	if o.URL != "" {
+		e.URL = o.URL
+	}
+
+	if o.Token != "" {
+		e.Token = o.Token
+	}
+
+	if o.Network != "" {
+		e.Network = o.Network
+	}
+
+	if o.Provider != "" {
+		e.Provider = o.Provider
+	}
+
+	if o.MinConfirmations != nil {
+		e.MinConfirmations = o.MinConfirmations
+	}
+
+	if len(o.Senders) != 0 {
+		e.Senders = o.Senders
+	}
+
+	if len(o.Recipients) != 0 {
+		e.Recipients = o.Recipients
+	}
+
+	if len(o.Alternates) != 0 {
+		e.Alternates = o.Alternates
+	}
+
+	if o.Quorum != nil {
+		e.Quorum = o.Quorum
+	}
+
+	return e
+}
+
+// withQuery returns the given URL with the access token as its `token` query parameter
+func (e Endpoint) withQuery(u string, query url.Values) string {
+	if e.Token != "" {
+		query.Set("token", e.Token)
+	}
+
+	if len(query) == 0 {
+		return u
+	}
+
+	return u + "?" + query.Encode()
+}
+
+// defaultEndpoints are the builtin endpoints keyed by anchor type
+var defaultEndpoints = map[string]Endpoint{
+	"eth": {
+		URL:     "https://rinkeby.infura.io/v3/" + tokenPlaceholder,
+		Network: "rinkeby",
+	},
+	"eth_mainnet": {
+		URL:     "https://mainnet.infura.io/v3/" + tokenPlaceholder,
+		Network: "mainnet",
+	},
+	"eth_elastos": {
+		URL:     "https://mainrpc.elaeth.io",
+		Network: "elastos",
+	},
+	"btc": {
+		URL:              blockCypherURL,
+		Network:          "test3",
+		MinConfirmations: int64Ptr(defaultBtcMinConfirmations),
+	},
+	"btc_mainnet": {
+		URL:              blockCypherURL,
+		Network:          "main",
+		MinConfirmations: int64Ptr(defaultBtcMinConfirmations),
+	},
+	"hedera": {
+		URL:     "https://testnet.mirrornode.hedera.com",
+		Network: "testnet",
+	},
+	"hedera_mainnet": {
+		URL:     "https://mainnet-public.mirrornode.hedera.com",
+		Network: "mainnet",
+	},
+}
+
+// Config represents the configuration of an anchor verification. Different configs can be used by
+// concurrent verifications
+type Config struct {
+	// VerifyIndependently indicates whether to verify a proof's anchor independently, which does
+	// not rely on the proof's anchor URI to do the verification
+	VerifyIndependently bool
+	// Endpoints are the blockchain APIs keyed by anchor type, such as `eth_mainnet`. The builtin
+	// endpoint of an anchor type is used when it is missing
+	Endpoints map[string]Endpoint
+	// BtcAnchorBranchType is the anchor type whose endpoint is used to verify `btc_anchor_branch`es,
+	// such as `btc` for Bitcoin testnet. It is detected from the anchor types of each branch when
+	// empty, where `btc` is mainnet and `tbtc` is testnet
+	BtcAnchorBranchType string
+	// BtcHeaders is the chain of Bitcoin block headers used to verify the Bitcoin block
+	// merkle roots offline, in which case the Bitcoin transaction of a `btc_anchor_branch` is not
+	// looked up either once any of its merkle roots is verified. The merkle roots are looked up
+	// using the endpoint when it is nil
+	BtcHeaders *HeaderChain
+	// Events receives the anchor verification events. No events are emitted when it is nil
+	Events event.Sink
+	// Lookups memoizes anchor lookups, which can be shared by the configs of different
+	// verifications. Nothing is memoized when it is nil
+	Lookups *LookupCache
+	// Cache persists the evidence of final anchor lookups across runs, such as the data of the
+	// blockchain transactions whose confirmations are checked to be deep enough in the chain.
+	// Nothing is persisted when it is nil
+	Cache *DiskCache
+	// Offline indicates whether to verify anchors without any network call, using only the
+	// supplied evidence, the disk cache and `BtcHeaders`. The anchors without evidence are
+	// unverifiable
+	Offline bool
+	// Evidence is the supplied evidence, which is used before the disk cache and the network. Its
+	// raw transactions and block headers are checked as if they were fetched, such as being hashed
+	// to their IDs
+	Evidence *EvidenceBundle
+	// Record collects the evidence that the verification relies on, which can be saved and
+	// supplied as `Evidence` to reproduce the verification offline. Nothing is collected when it
+	// is nil
+	Record *EvidenceBundle
+	// Registry has the verifiers used to verify anchors independently. `DefaultRegistry` is used
+	// when it is nil
+	Registry *Registry
+}
+
+// FileConfig represents a config file in JSON
+type FileConfig struct {
+	// Endpoints are the endpoints keyed by anchor type, whose non-empty fields override the builtin
+	// ones
+	Endpoints map[string]Endpoint `json:"endpoints"`
+}
+
+func (c *Config) registry() *Registry {
+	if c.Registry == nil {
+		return DefaultRegistry
+	}
+
+	return c.Registry
+}
+
+// Endpoint returns the endpoint of the given anchor type
+func (c *Config) Endpoint(anchorType string) Endpoint {
+	if e, ok := c.Endpoints[anchorType]; ok {
+		return e
+	}
+
+	return defaultEndpoints[anchorType]
+}
+
+// SetEndpoint overrides the endpoint of the given anchor type with the non-empty fields of the
+// given one
+func (c *Config) SetEndpoint(anchorType string, e Endpoint) {
+	if c.Endpoints == nil {
+		c.Endpoints = make(map[string]Endpoint)
+	}
+
+	c.Endpoints[anchorType] = c.Endpoint(anchorType).merge(e)
+}
+
+// DefaultConfig creates a config whose defaults are seeded from the build time BlockCypher and
+// Infura tokens, the config file given by `PROVENDB_VERIFY_CONFIG` and the `PROVENDB_VERIFY_*` environment
+// variables
+func DefaultConfig() (*Config, error) {
+	return NewConfig(os.Getenv(envConfig))
+}
+
+// NewConfig creates a config whose defaults are seeded from the build time BlockCypher and Infura
+// tokens, the given config file and the `PROVENDB_VERIFY_*` environment variables, in increasing
+// precedence. The config file is skipped when the filename is empty
+func NewConfig(filename string) (*Config, error) {
+	cfg := &Config{}
+
+	if bcToken != "" {
+		cfg.SetEndpoint("btc", Endpoint{Token: bcToken})
+		cfg.SetEndpoint("btc_mainnet", Endpoint{Token: bcToken})
+	}
+
+	if infuraToken != "" {
+		cfg.SetEndpoint("eth", Endpoint{Token: infuraToken})
+		cfg.SetEndpoint("eth_mainnet", Endpoint{Token: infuraToken})
+	}
+
+	if filename != "" {
+		err := cfg.loadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := os.LookupEnv(envBCToken); ok {
+		cfg.SetEndpoint("btc", Endpoint{Token: v})
+		cfg.SetEndpoint("btc_mainnet", Endpoint{Token: v})
+	}
+
+	if v, ok := os.LookupEnv(envVerifyAnchorIndependently); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `%s`: %s", envVerifyAnchorIndependently, err)
+		}
+
+		cfg.VerifyIndependently = b
+	}
+
+	err := cfg.loadEnv(os.Environ())
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("cannot load config file: %s", err)
+	}
+
+	var fc FileConfig
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(&fc)
+	if err != nil {
+		return fmt.Errorf("cannot load config file `%s`: %s", filename, err)
+	}
+
+	for t, e := range fc.Endpoints {
+		err := e.Validate()
+		if err != nil {
+			return fmt.Errorf("cannot load config file `%s`: endpoint `%s`: %s", filename, t, err)
+		}
+
+		c.SetEndpoint(t, e)
+	}
+
+	return nil
+}
+
+// loadEnv loads the endpoints from the environment variables in the form of
+// `PROVENDB_VERIFY_ENDPOINT_<ANCHOR_TYPE>_<URL|TOKEN|NETWORK|PROVIDER|MIN_CONFIRMATIONS|SENDERS|RECIPIENTS|QUORUM>`,
+// such as `PROVENDB_VERIFY_ENDPOINT_ETH_MAINNET_URL`. The addresses are comma separated
+func (c *Config) loadEnv(environ []string) error {
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, envEndpointPrefix) {
+			continue
+		}
+
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			continue
+		}
+
+		key, value := kv[len(envEndpointPrefix):i], kv[i+1:]
+
+		if strings.HasSuffix(key, envMinConfirmationsSuffix) {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid `%s`: %s", kv[:i], err)
+			}
+
+			anchorType := strings.ToLower(strings.TrimSuffix(key, envMinConfirmationsSuffix))
+			c.SetEndpoint(anchorType, Endpoint{MinConfirmations: &n})
+			continue
+		}
+
+		j := strings.LastIndex(key, "_")
+		if j <= 0 {
+			continue
+		}
+
+		anchorType := strings.ToLower(key[:j])
+
+		switch key[j+1:] {
+		case "URL":
+			c.SetEndpoint(anchorType, Endpoint{URL: value})
+		case "TOKEN":
+			c.SetEndpoint(anchorType, Endpoint{Token: value})
+		case "NETWORK":
+			c.SetEndpoint(anchorType, Endpoint{Network: value})
+		case "PROVIDER":
+			c.SetEndpoint(anchorType, Endpoint{Provider: value})
+		case "SENDERS", "RECIPIENTS":
+			addresses := strings.Split(value, ",")
+
+			e := Endpoint{Senders: addresses}
+			if key[j+1:] == "RECIPIENTS" {
+				e = Endpoint{Recipients: addresses}
+			}
+
+			err := e.Validate()
+			if err != nil {
+				return fmt.Errorf("invalid `%s`: %s", kv[:i], err)
+			}
+
+			c.SetEndpoint(anchorType, e)
+		case "QUORUM":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid `%s`: %s", kv[:i], err)
+			}
+
+			c.SetEndpoint(anchorType, Endpoint{Quorum: &n})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T20:12:09+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T20:12:09+11:00
+ */
+
+package anchor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+
+	err := ioutil.WriteFile(filename, []byte(`{
+		"endpoints": {
+			"eth_mainnet": {"url": "http://localhost:8545", "minConfirmations": 12},
+			"btc_mainnet": {"token": "file-token", "network": "regtest", "alternates": [{"provider": "esplora"}],
+				"quorum": 3},
+			"eth_custom": {"url": "http://localhost:8546/{token}", "token": "file-token"}
+		}
+	}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(envConfig, filename)
+	t.Setenv(envBCToken, "env-token")
+	t.Setenv("PROVENDB_VERIFY_ENDPOINT_ETH_TOKEN", "env-token")
+	t.Setenv("PROVENDB_VERIFY_ENDPOINT_ETH_CUSTOM_TOKEN", "env-token")
+	t.Setenv("PROVENDB_VERIFY_ENDPOINT_HEDERA_MAINNET_URL", "http://localhost:5551/")
+	// an explicit zero overrides the config file
+	t.Setenv("PROVENDB_VERIFY_ENDPOINT_ETH_MAINNET_MIN_CONFIRMATIONS", "0")
+	t.Setenv("PROVENDB_VERIFY_ENDPOINT_BTC_MAINNET_QUORUM", "2")
+
+	cfg, err := DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		anchorType string
+		want       Endpoint
+		wantURL    string
+	}{
+		{
+			"eth_mainnet",
+			Endpoint{URL: "http://localhost:8545", Network: "mainnet", MinConfirmations: int64Ptr(0)},
+			"http://localhost:8545",
+		},
+		{
+			"eth",
+			Endpoint{URL: "https://rinkeby.infura.io/v3/{token}", Token: "env-token", Network: "rinkeby"},
+			"https://rinkeby.infura.io/v3/env-token",
+		},
+		{
+			"btc_mainnet",
+			Endpoint{URL: "https://api.blockcypher.com/v1/btc", Token: "env-token", Network: "regtest",
+				MinConfirmations: int64Ptr(6), Alternates: []Endpoint{{Provider: ProviderEsplora}}, Quorum: int64Ptr(2)},
+			"https://api.blockcypher.com/v1/btc",
+		},
+		{
+			"eth_custom",
+			Endpoint{URL: "http://localhost:8546/{token}", Token: "env-token"},
+			"http://localhost:8546/env-token",
+		},
+		{
+			"hedera_mainnet",
+			Endpoint{URL: "http://localhost:5551/", Network: "mainnet"},
+			"http://localhost:5551/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.anchorType, func(t *testing.T) {
+			got := cfg.Endpoint(tt.anchorType)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Endpoint() = %+v, want %+v", got, tt.want)
+			}
+
+			if u := got.resolvedURL(); u != tt.wantURL {
+				t.Errorf("resolvedURL() = %s, want %s", u, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestEndpointCheckToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		ep      Endpoint
+		wantErr bool
+	}{
+		{
+			"Builtin Infura endpoint without token",
+			defaultEndpoints["eth_mainnet"],
+			true,
+		},
+		{
+			"Builtin Infura endpoint with token",
+			defaultEndpoints["eth_mainnet"].merge(Endpoint{Token: "token"}),
+			false,
+		},
+		{
+			"Keyless endpoint",
+			defaultEndpoints["eth_elastos"],
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ep.checkToken(); (err != nil) != tt.wantErr {
+				t.Errorf("checkToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewConfigInvalidFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+
+	err := ioutil.WriteFile(filename, []byte(`{"endpoint": {}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewConfig(filename); err == nil {
+		t.Errorf("NewConfig() error = nil, want unknown field error")
+	}
+
+	if _, err := NewConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("NewConfig() error = nil, want missing file error")
+	}
+
+	t.Setenv("PROVENDB_VERIFY_ENDPOINT_BTC_MIN_CONFIRMATIONS", "six")
+
+	if _, err := NewConfig(""); err == nil {
+		t.Errorf("NewConfig() error = nil, want invalid min confirmations error")
+	}
+}
+
+func TestNewConfigInvalidAddress(t *testing.T) {
+	const address = "0x2f3ca2a1d6de4a3b2ef9c4e3e8dd5ff35b8e1b7a"
+
+	tests := []struct {
+		name   string
+		config string
+		env    string
+	}{
+		{
+			"Invalid sender in config file",
+			`{"endpoints": {"eth": {"senders": ["` + address + `", "0xprovendb"]}}}`,
+			"",
+		},
+		{
+			"Invalid recipient in config file",
+			`{"endpoints": {"eth": {"alternates": [{"recipients": ["0xprovendb"]}]}}}`,
+			"",
+		},
+		{
+			"Invalid sender in environment variable",
+			`{}`,
+			address + ",0xprovendb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "config.json")
+
+			err := ioutil.WriteFile(filename, []byte(tt.config), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.env != "" {
+				t.Setenv("PROVENDB_VERIFY_ENDPOINT_ETH_SENDERS", tt.env)
+			}
+
+			_, err = NewConfig(filename)
+			if err == nil || !strings.Contains(err.Error(), "`0xprovendb`") {
+				t.Errorf("NewConfig() error = %v, want invalid address `0xprovendb`", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:59:12+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:59:12+11:00
+ */
+
+package anchor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DiskCache persists the evidence of anchor lookups in a directory across runs, where confirmed
+// blockchain data and anchor URI bodies are immutable. Each evidence is stored in a file named by
+// the SHA-256 of its key. It is safe for concurrent use
+type DiskCache struct {
+	// Dir is the cache directory
+	Dir string
+}
+
+// NewDiskCache creates a new DiskCache in the given directory
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{Dir: dir}
+}
+
+// DefaultCacheDir returns the default cache directory in the user cache directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "provendb-verify"), nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+
+	return filepath.Join(c.Dir, name[:2], name+".json")
+}
+
+// Get gets the evidence of the given key. It returns nil when the evidence is missing or unreadable
+func (c *DiskCache) Get(key string) *Evidence {
+	if c == nil {
+		return nil
+	}
+
+	e, err := readEvidence(c.path(key))
+	if err != nil || e.Key != key {
+		return nil
+	}
+
+	return e
+}
+
+// evidenceTempPrefix is the prefix of the temp files written by `Put`
+const evidenceTempPrefix = ".evidence-"
+
+// Put stores the given evidence
+func (c *DiskCache) Put(e *Evidence) error {
+	if c == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	p := c.path(e.Key)
+
+	err = os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file first, so concurrent readers never see a partial evidence
+	f, err := ioutil.TempFile(filepath.Dir(p), evidenceTempPrefix)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), p)
+}
+
+// Prune removes the evidence fetched longer than the given duration ago, or all the evidence when
+// the duration is zero, along with the leftover temp files of `Put`. Only the files laid out by
+// `Put` are removed, so the other files in the directory are kept, even when it is not a cache
+// directory. It returns the number of removed files
+func (c *DiskCache) Prune(olderThan time.Duration) (int, error) {
+	var (
+		deadline = time.Now().Add(-olderThan)
+		n        int
+	)
+
+	err := filepath.Walk(c.Dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		rel, err := filepath.Rel(c.Dir, p)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			// only the subdirectories named by the first 2 hex digits of the SHA-256s are walked
+			if rel != "." && !isHexName(rel, 2) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		dir, name := filepath.Split(rel)
+		if dir == "" {
+			return nil
+		}
+
+		switch {
+		case strings.HasPrefix(name, evidenceTempPrefix):
+			if olderThan > 0 && info.ModTime().After(deadline) {
+				// it may still be being written
+				return nil
+			}
+		case isHexName(strings.TrimSuffix(name, ".json"), sha256.Size*2) &&
+			strings.HasPrefix(name, filepath.Clean(dir)):
+			e, err := readEvidence(p)
+			if err != nil || e.Key == "" || c.path(e.Key) != p {
+				return nil
+			}
+
+			if olderThan > 0 && e.FetchedAt.After(deadline) {
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		err = os.Remove(p)
+		if err != nil {
+			return err
+		}
+
+		n++
+		return nil
+	})
+
+	return n, err
+}
+
+// isHexName reports whether the given name is made of the given number of lowercase hex digits
+func isHexName(name string, n int) bool {
+	if len(name) != n {
+		return false
+	}
+
+	for _, r := range name {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func readEvidence(filename string) (*Evidence, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Evidence{}
+
+	err = json.Unmarshal(data, e)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
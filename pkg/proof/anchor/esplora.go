@@ -0,0 +1,156 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T22:31:17+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T22:31:17+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/httputil"
+)
+
+// esploraURLs are the public Esplora APIs of Blockstream keyed by network, which are used when the
+// URL of an Esplora endpoint is left as the BlockCypher one
+var esploraURLs = map[string]string{
+	"main":  "https://blockstream.info/api",
+	"test3": "https://blockstream.info/testnet/api",
+}
+
+// esplora is the Esplora REST API backend, which is also served by mempool.space
+type esplora struct {
+	url string
+}
+
+func newEsplora(ep Endpoint) *esplora {
+	url := ep.resolvedURL()
+
+	if u, ok := esploraURLs[ep.Network]; ok && ep.URL == blockCypherURL {
+		url = u
+	}
+
+	return &esplora{
+		url: strings.TrimSuffix(url, "/"),
+	}
+}
+
+func (e *esplora) txURI(txID string) string {
+	return e.url + "/tx/" + txID
+}
+
+func (e *esplora) tx(ctx context.Context, txID string) (*rawEvidence, error) {
+	// the raw transaction is got rather than the decoded one, so it can be hashed to its ID
+	txHex, err := e.getText(ctx, e.txURI(txID)+"/hex")
+	if err != nil {
+		return nil, err
+	}
+
+	var txStatus json.RawMessage
+
+	err = httputil.UnmarshalHTTPGetJSON(ctx, e.txURI(txID)+"/status", &txStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRawEvidence(esploraTxFormat, rawText(txHex), txStatus), nil
+}
+
+func (e *esplora) blockURI(height string) string {
+	return e.url + "/block-height/" + height
+}
+
+func (e *esplora) block(ctx context.Context, height string) (*rawEvidence, error) {
+	hash, err := e.getText(ctx, e.blockURI(height))
+	if err != nil {
+		return nil, err
+	}
+
+	headerHex, err := e.getText(ctx, e.url+"/block/"+hash+"/header")
+	if err != nil {
+		return nil, err
+	}
+
+	return newRawEvidence(esploraBlockFormat, rawText(hash), rawText(headerHex)), nil
+}
+
+func (e *esplora) txConfirmations(ctx context.Context, txID string) (int64, error) {
+	var txStatus struct {
+		Confirmed   bool  `json:"confirmed"`
+		BlockHeight int64 `json:"block_height"`
+	}
+
+	err := httputil.UnmarshalHTTPGetJSON(ctx, e.txURI(txID)+"/status", &txStatus)
+	if err != nil {
+		return 0, err
+	}
+
+	if !txStatus.Confirmed {
+		return 0, nil
+	}
+
+	tip, err := e.tipHeight(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return confirmations(tip, txStatus.BlockHeight), nil
+}
+
+func (e *esplora) tipURI() string {
+	return e.url + "/blocks/tip/height"
+}
+
+func (e *esplora) tipHeight(ctx context.Context) (int64, error) {
+	text, err := e.getText(ctx, e.tipURI())
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Bitcoin chain tip height `%s`: %s", text, err)
+	}
+
+	return height, nil
+}
+
+// getText gets the URL result as a trimmed text
+func (e *esplora) getText(ctx context.Context, url string) (string, error) {
+	body, err := httputil.HTTPGet(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
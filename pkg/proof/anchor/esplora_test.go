@@ -0,0 +1,326 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T22:48:05+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T22:48:05+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+func newTestEsplora(t *testing.T) *httptest.Server {
+	header, blockHash := testBlockHeader(t)
+	txID := testTxID(t, false)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blocks/tip/height", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("503280"))
+	})
+	mux.HandleFunc("/tx/", func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/tx/") {
+		case txID + "/hex", testForgedTxID + "/hex":
+			w.Write([]byte(testRawTx(false)))
+		case txID + "/status", testForgedTxID + "/status":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"confirmed":    true,
+				"block_height": 503275,
+				"block_time":   testBlockTime,
+			})
+		default:
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/block-height/503275", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(blockHash))
+	})
+	mux.HandleFunc("/block/"+blockHash+"/header", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(header))
+	})
+	mux.HandleFunc("/block-height/503276", func(w http.ResponseWriter, r *http.Request) {
+		// a hash, whose header does not match
+		w.Write([]byte(strings.Repeat("00", 32)))
+	})
+	mux.HandleFunc("/block/"+strings.Repeat("00", 32)+"/header", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(header))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestEsplora(t *testing.T) {
+	ts := newTestEsplora(t)
+	defer ts.Close()
+
+	ep := Endpoint{URL: ts.URL + "/", Provider: ProviderEsplora}
+	txID := testTxID(t, false)
+
+	tests := []struct {
+		name          string
+		txID          string
+		expectedValue string
+		wantErr       error
+	}{
+		{
+			"Verify transaction",
+			txID,
+			testOpReturnValue,
+			nil,
+		},
+		{
+			"Falsify transaction",
+			txID,
+			strings.Repeat("00", 32),
+			status.ErrAnchorValueMismatch,
+		},
+		{
+			"Missing transaction",
+			"missing",
+			testOpReturnValue,
+			status.ErrAnchorUnreachable,
+		},
+		{
+			"Forged transaction",
+			testForgedTxID,
+			testOpReturnValue,
+			status.ErrAnchorUnreachable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, true)
+			err := v.verifyBtcTxnData(context.Background(), Result{}, tt.txID, tt.expectedValue, ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyBtcTxnData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			r := v.results[0]
+
+			if r.URI != ts.URL+"/tx/"+tt.txID {
+				t.Errorf("verifyBtcTxnData() recorded URI %s", r.URI)
+			}
+
+			if tt.wantErr == nil && (r.AnchoredAt == nil || r.AnchoredAt.Unix() != testBlockTime) {
+				t.Errorf("verifyBtcTxnData() recorded anchoring time %v", r.AnchoredAt)
+			}
+		})
+	}
+
+	blockTests := []struct {
+		name    string
+		height  string
+		wantErr error
+	}{
+		{
+			"Verify block merkle root",
+			"503275",
+			nil,
+		},
+		{
+			"Mismatched block header",
+			"503276",
+			status.ErrAnchorUnreachable,
+		},
+	}
+	for _, tt := range blockTests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, true)
+			err := v.verifyBitcoinBlockMerkleRoot(context.Background(), Result{}, tt.height, testOpReturnValue, ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyBitcoinBlockMerkleRoot() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if r := v.results[0]; tt.wantErr == nil && (r.AnchoredAt == nil || r.AnchoredAt.Unix() != testHeaderTime) {
+				t.Errorf("verifyBitcoinBlockMerkleRoot() recorded anchoring time %v", r.AnchoredAt)
+			}
+		})
+	}
+}
+
+func TestEsploraMinConfirmations(t *testing.T) {
+	ts := newTestEsplora(t)
+	defer ts.Close()
+
+	tests := []struct {
+		name             string
+		minConfirmations int64
+		wantErr          error
+	}{
+		{
+			"No policy",
+			0,
+			nil,
+		},
+		{
+			"Enough confirmations",
+			6,
+			nil,
+		},
+		{
+			"Insufficient confirmations",
+			7,
+			status.ErrInsufficientConfirmations,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := Endpoint{URL: ts.URL, Provider: ProviderEsplora, MinConfirmations: &tt.minConfirmations}
+			v := newTestVerifier(t, true)
+
+			err := v.verifyBtcTxnData(context.Background(), Result{}, testTxID(t, false), testOpReturnValue, ep)
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyBtcTxnData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			err = v.verifyBitcoinBlockMerkleRoot(context.Background(), Result{}, "503275", testOpReturnValue, ep)
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyBitcoinBlockMerkleRoot() error = %v, want %v", err, tt.wantErr)
+			}
+
+			for _, r := range v.results {
+				if tt.wantErr != nil && r.Status != status.VerificationStatusUnverifiable {
+					t.Errorf("recorded status %s, want %s", r.Status, status.VerificationStatusUnverifiable)
+				}
+			}
+		})
+	}
+}
+
+func Test_newEsplora(t *testing.T) {
+	tests := []struct {
+		name string
+		ep   Endpoint
+		want string
+	}{
+		{
+			"Blockstream mainnet by default",
+			Endpoint{URL: blockCypherURL, Network: "main", Provider: ProviderEsplora},
+			"https://blockstream.info/api",
+		},
+		{
+			"Blockstream testnet by default",
+			Endpoint{URL: blockCypherURL, Network: "test3", Provider: ProviderEsplora},
+			"https://blockstream.info/testnet/api",
+		},
+		{
+			"Custom URL",
+			Endpoint{URL: "https://mempool.space/api/", Network: "main", Provider: ProviderEsplora},
+			"https://mempool.space/api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newEsplora(tt.ep).url; got != tt.want {
+				t.Errorf("newEsplora() url = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_verifyBitcoinBranchNetwork(t *testing.T) {
+	ts := newTestEsplora(t)
+	defer ts.Close()
+
+	// an endpoint that is always unreachable
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	tests := []struct {
+		name       string
+		anchorType string
+		branchType string
+		endpoints  map[string]string
+		wantType   string
+	}{
+		{
+			"Detect testnet",
+			"tbtc",
+			"",
+			map[string]string{"btc": ts.URL, "btc_mainnet": down.URL},
+			"btc",
+		},
+		{
+			"Detect mainnet",
+			"btc",
+			"",
+			map[string]string{"btc": down.URL, "btc_mainnet": ts.URL},
+			"btc_mainnet",
+		},
+		{
+			"Configure testnet",
+			"btc",
+			"btc",
+			map[string]string{"btc": ts.URL, "btc_mainnet": down.URL},
+			"btc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, true)
+			v.cfg.BtcAnchorBranchType = tt.branchType
+
+			for anchorType, url := range tt.endpoints {
+				v.cfg.SetEndpoint(anchorType, Endpoint{URL: url, Provider: ProviderEsplora})
+			}
+
+			branch := &model.EvaluatedBranch{
+				Label:         btcAnchorBranch,
+				BtcTxID:       testTxID(t, false),
+				OpReturnValue: testOpReturnValue,
+				Anchors: []model.EvaluatedAnchor{
+					{
+						Type:          tt.anchorType,
+						AnchorID:      "503275",
+						ExpectedValue: testOpReturnValue,
+						Op:            40,
+					},
+				},
+			}
+
+			err := v.verifyBitcoinBranch(context.Background(), []string{btcAnchorBranch}, branch)
+			if err != nil {
+				t.Fatalf("verifyBitcoinBranch() error = %v", err)
+			}
+
+			for _, r := range v.results {
+				if !strings.HasPrefix(r.URI, ts.URL) {
+					t.Errorf("verifyBitcoinBranch() recorded URI %s", r.URI)
+				}
+
+				if r.Check == CheckBtcTxOpReturn && r.Type != tt.wantType {
+					t.Errorf("verifyBitcoinBranch() recorded type %s, want %s", r.Type, tt.wantType)
+				}
+			}
+		})
+	}
+}
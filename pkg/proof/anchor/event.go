@@ -0,0 +1,46 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T14:05:12+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T14:05:12+11:00
+ */
+
+package anchor
+
+// AnchorStarted is emitted when a check against an anchor starts. `Result` only has the check
+// identity filled in, such as `Branch`, `Check`, `URI` and `ExpectedValue`
+type AnchorStarted struct {
+	Result Result
+}
+
+// EventName implements `event.Event`
+func (AnchorStarted) EventName() string {
+	return "anchor.started"
+}
+
+// AnchorFinished is emitted when a check against an anchor finishes, including the skipped ones
+type AnchorFinished struct {
+	Result Result
+}
+
+// EventName implements `event.Event`
+func (AnchorFinished) EventName() string {
+	return "anchor.finished"
+}
@@ -0,0 +1,537 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:59:12+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:59:12+11:00
+ */
+
+package anchor
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+// Evidence represents an external response that an anchor verification relies on, such as the data
+// of a blockchain transaction or the body returned by an anchor URI
+type Evidence struct {
+	// Key identifies the evidence by network and ID, such as `btc/main/tx/TX_ID`
+	Key string `json:"key"`
+	// URL is the source of the evidence, whose access token is not shown
+	URL string `json:"url"`
+	// FetchedAt is when the evidence is fetched
+	FetchedAt time.Time `json:"fetchedAt"`
+	// Value is the evidence in JSON, which keeps the raw responses of the provider for a
+	// transaction or block
+	Value json.RawMessage `json:"value"`
+	// pending indicates whether the evidence is fetched in this run and is cached on disk once it
+	// is confirmed deep enough in the chain
+	pending bool
+}
+
+// evidenceBundleEntry is the filename of the evidence bundle in a zip
+const evidenceBundleEntry = "evidence.json"
+
+// EvidenceBundle is a collection of evidence keyed by key, which can be used to verify anchors
+// offline. It is safe for concurrent use
+type EvidenceBundle struct {
+	mu       sync.RWMutex
+	evidence map[string]*Evidence
+}
+
+// NewEvidenceBundle creates an empty EvidenceBundle
+func NewEvidenceBundle() *EvidenceBundle {
+	return &EvidenceBundle{
+		evidence: make(map[string]*Evidence),
+	}
+}
+
+// LoadEvidenceBundle loads an EvidenceBundle from the given JSON file, or the `evidence.json` in
+// the given zip
+func LoadEvidenceBundle(filename string) (*EvidenceBundle, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if filepath.Ext(filename) == ".zip" {
+		data, err = readZipEntry(filename, evidenceBundleEntry)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b := NewEvidenceBundle()
+
+	err = json.Unmarshal(data, b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evidence bundle `%s`: %s", filename, err)
+	}
+
+	return b, nil
+}
+
+// Len returns the number of evidence
+func (b *EvidenceBundle) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.evidence)
+}
+
+// Get gets the evidence of the given key. It returns nil when the evidence is missing
+func (b *EvidenceBundle) Get(key string) *Evidence {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.evidence[key]
+}
+
+// Add adds the given evidence, which replaces the one with the same key
+func (b *EvidenceBundle) Add(e *Evidence) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.evidence[e.Key] = e
+}
+
+// WriteFile writes the bundle to the given JSON file, or as the `evidence.json` in the given zip
+func (b *EvidenceBundle) WriteFile(filename string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if filepath.Ext(filename) != ".zip" {
+		return ioutil.WriteFile(filename, data, 0644)
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	w, err := zw.Create(evidenceBundleEntry)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// readZipEntry reads the file of the given name in the given zip
+func readZipEntry(filename, name string) ([]byte, error) {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		return ioutil.ReadAll(rc)
+	}
+
+	return nil, fmt.Errorf("`%s` is not found in `%s`", name, filename)
+}
+
+type evidenceBundleJSON struct {
+	Evidence []*Evidence `json:"evidence"`
+}
+
+// MarshalJSON implements `json.Marshaler`, where the evidence is sorted by key
+func (b *EvidenceBundle) MarshalJSON() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	j := evidenceBundleJSON{
+		Evidence: make([]*Evidence, 0, len(b.evidence)),
+	}
+
+	for _, e := range b.evidence {
+		j.Evidence = append(j.Evidence, e)
+	}
+
+	sort.Slice(j.Evidence, func(i, k int) bool {
+		return j.Evidence[i].Key < j.Evidence[k].Key
+	})
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (b *EvidenceBundle) UnmarshalJSON(data []byte) error {
+	var j evidenceBundleJSON
+
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.evidence = make(map[string]*Evidence, len(j.Evidence))
+
+	for _, e := range j.Evidence {
+		if e.Key == "" {
+			return fmt.Errorf("evidence from `%s` has no key", e.URL)
+		}
+
+		b.evidence[e.Key] = e
+	}
+
+	return nil
+}
+
+// finaler is implemented by the evidence values that may still change until they are deep enough
+// in the chain, such as a transaction, which can be reorganised out of its block. Only the final
+// ones are cached on disk, once they have the `finalConfirmations` of their chain
+type finaler interface {
+	final() bool
+}
+
+// finalConfirmations are the minimum numbers of confirmations keyed by chain, after which the
+// anchoring transactions and blocks are cached on disk
+var finalConfirmations = map[string]int64{
+	"btc": 6,
+	"eth": 12,
+}
+
+func (b *btcBlock) final() bool {
+	return true
+}
+
+func (t *btcTx) final() bool {
+	return !t.BlockTime.IsZero()
+}
+
+func (t *ethTx) final() bool {
+	return t.Canonical
+}
+
+// chainCount is a number that grows with the chain, such as the tip height or the confirmations of
+// a transaction
+type chainCount struct {
+	Count int64 `json:"count"`
+}
+
+func (c *chainCount) final() bool {
+	return false
+}
+
+// rawEvidence is the value of the evidence of a transaction or block, which keeps the responses of
+// its provider as they are. It is parsed and checked again whenever it is used, so the evidence
+// supplied offline goes through the same checks as a fetched one, such as hashing a raw
+// transaction to its ID
+type rawEvidence struct {
+	// Format names the provider and the kind of the responses, such as `bitcoind/tx`
+	Format string `json:"format"`
+	// Responses are the results of the requests to the provider in order, each of which is either a
+	// JSON body or a text in a JSON string
+	Responses []json.RawMessage `json:"responses"`
+	// parsed is the value parsed from the evidence when it is fetched, which decides whether the
+	// evidence is final
+	parsed interface{}
+}
+
+// newRawEvidence creates a `rawEvidence` of the given format from the given responses
+func newRawEvidence(format string, responses ...json.RawMessage) *rawEvidence {
+	return &rawEvidence{
+		Format:    format,
+		Responses: responses,
+	}
+}
+
+// rawText encodes the given text response as a JSON string
+func rawText(text string) json.RawMessage {
+	raw, _ := json.Marshal(text)
+	return raw
+}
+
+// response decodes the response of the given index into the given pointer
+func (r *rawEvidence) response(i int, value interface{}) error {
+	if i >= len(r.Responses) {
+		return fmt.Errorf("evidence of format `%s` has %d responses, but expect at least %d",
+			r.Format, len(r.Responses), i+1)
+	}
+
+	err := json.Unmarshal(r.Responses[i], value)
+	if err != nil {
+		return fmt.Errorf("invalid response %d in evidence of format `%s`: %s", i, r.Format, err)
+	}
+
+	return nil
+}
+
+// errUnsupported returns the error of an evidence format that the parser does not support
+func (r *rawEvidence) errUnsupported() error {
+	return fmt.Errorf("evidence format `%s` is not supported", r.Format)
+}
+
+// evidenceKey creates the key of the evidence of the given kind, such as `tx`, and ID in the
+// network of the given result and endpoint. The ID is omitted when empty, such as for `tip`
+func evidenceKey(chain string, res Result, ep Endpoint, kind, id string) string {
+	network := ep.Network
+	if network == "" {
+		network = res.Type
+	}
+
+	parts := []string{chain, network, kind}
+	if id != "" {
+		parts = append(parts, id)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// uriEvidenceKey creates the key of the evidence of the given anchor URI
+func uriEvidenceKey(uri string) string {
+	return "uri/" + uri
+}
+
+// RedactURL removes the access token from the given URL, which is either the password of its user
+// info, its `token` query parameter or the Infura project ID in its path. The URL is returned as is
+// when it has no access token, so a token placeholder is kept
+func RedactURL(u string) string {
+	p, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+
+	redacted := false
+
+	if _, ok := p.User.Password(); ok {
+		p.User = url.User(p.User.Username())
+		redacted = true
+	}
+
+	if q := p.Query(); q.Get("token") != "" {
+		q.Del("token")
+		p.RawQuery = q.Encode()
+		redacted = true
+	}
+
+	if strings.HasSuffix(p.Hostname(), "infura.io") {
+		if id := strings.TrimPrefix(p.Path, "/v3/"); id != p.Path && id != "" && id != tokenPlaceholder {
+			p.Path, p.RawPath = "/v3/", ""
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return u
+	}
+
+	return p.String()
+}
+
+// lookup gets the evidence of the given key and decodes its value into the given pointer. When
+// the evidence is neither memoized nor cached on disk, it is fetched from the given URL using the
+// given function
+func (v *verifier) lookup(ctx context.Context, key, uri string, value interface{},
+	fetch func() (interface{}, error)) error {
+	e, err := v.cfg.Lookups.get(ctx, key, func() (interface{}, error) {
+		return v.fetchEvidence(key, uri, fetch)
+	})
+	if err != nil {
+		return err
+	}
+
+	v.cfg.Record.Add(e.(*Evidence))
+
+	return json.Unmarshal(e.(*Evidence).Value, value)
+}
+
+// lookupRaw gets the raw evidence of the given key and parses it into the given value using the
+// given function, which runs whether the evidence is fetched, supplied or cached on disk. When the
+// evidence is neither memoized nor cached on disk, it is fetched from the given URL using the given
+// function and parsed before it is cached. The evidence is returned to be cached on disk by
+// `cacheConfirmed`
+func (v *verifier) lookupRaw(ctx context.Context, key, uri string, value interface{},
+	parse func(r *rawEvidence) error, fetch func() (*rawEvidence, error)) (*Evidence, error) {
+	e, err := v.cfg.Lookups.get(ctx, key, func() (interface{}, error) {
+		return v.fetchEvidence(key, uri, func() (interface{}, error) {
+			r, err := fetch()
+			if err != nil {
+				return nil, err
+			}
+
+			err = parse(r)
+			if err != nil {
+				return nil, err
+			}
+
+			r.parsed = value
+
+			return r, nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v.cfg.Record.Add(e.(*Evidence))
+
+	r := &rawEvidence{}
+
+	err = json.Unmarshal(e.(*Evidence).Value, r)
+	if err != nil {
+		return e.(*Evidence), fmt.Errorf("invalid evidence `%s`: %s", key, err)
+	}
+
+	return e.(*Evidence), parse(r)
+}
+
+// cacheConfirmed caches the given evidence on disk when it is fetched in this run and has the
+// final number of confirmations of the given chain
+func (v *verifier) cacheConfirmed(e *Evidence, chain string, confirmations int64) {
+	if e != nil && e.pending && confirmations >= finalConfirmations[chain] {
+		v.cfg.Cache.Put(e)
+	}
+}
+
+// lookupCount gets the chain count of the given key, which is never memoized as it grows. It is
+// fetched from the given URL using the given function unless the evidence is supplied
+func (v *verifier) lookupCount(key, uri string, fetch func() (int64, error)) (int64, error) {
+	e, err := v.fetchEvidence(key, uri, func() (interface{}, error) {
+		n, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		return &chainCount{Count: n}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	v.cfg.Record.Add(e)
+
+	c := &chainCount{}
+
+	err = json.Unmarshal(e.Value, c)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Count, nil
+}
+
+// fetchEvidence gets the evidence of the given key from the supplied evidence bundle or the disk
+// cache, or fetches it from the given URL using the given function when not offline
+func (v *verifier) fetchEvidence(key, uri string, fetch func() (interface{}, error)) (*Evidence, error) {
+	if e := v.cfg.Evidence.Get(key); e != nil {
+		return e, nil
+	}
+
+	if e := v.cfg.Cache.Get(key); e != nil {
+		return e, nil
+	}
+
+	if v.cfg.Offline {
+		return nil, errEvidenceMissing(key)
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Evidence{
+		Key:       key,
+		URL:       RedactURL(uri),
+		FetchedAt: time.Now().UTC(),
+		Value:     raw,
+	}
+
+	// a raw evidence is as final as its parsed value
+	final := value
+	if r, ok := value.(*rawEvidence); ok {
+		final = r.parsed
+	}
+
+	if f, ok := final.(finaler); !ok {
+		// the disk cache is best effort, so the verification goes on when it cannot be written
+		v.cfg.Cache.Put(e)
+	} else {
+		e.pending = f.final()
+	}
+
+	return e, nil
+}
+
+// errEvidenceMissing creates the error of the missing evidence of the given key in offline mode
+func errEvidenceMissing(key string) error {
+	return status.NewCodedError(
+		status.VerificationStatusUnverifiable,
+		status.CodeEvidenceMissing,
+		fmt.Errorf("cannot verify offline without the evidence of `%s`", key),
+	)
+}
@@ -0,0 +1,356 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:59:44+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:59:44+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func newTestEvidence(t *testing.T, key string, value interface{}) *Evidence {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Evidence{
+		Key:       key,
+		URL:       "https://" + key,
+		FetchedAt: time.Unix(testBlockTime, 0).UTC(),
+		Value:     raw,
+	}
+}
+
+func TestEvidenceBundle(t *testing.T) {
+	b := NewEvidenceBundle()
+	b.Add(newTestEvidence(t, "uri/https://anchor.provendb.com/eth/0x02", testOpReturnValue))
+	b.Add(newTestEvidence(t, "btc/main/tx/found",
+		newRawEvidence(esploraTxFormat, rawText(testRawTx(false)), json.RawMessage(`{"confirmed": false}`))))
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	if i, k := strings.Index(string(data), "btc/main/tx/found"), strings.Index(string(data), "uri/"); i > k {
+		t.Errorf("MarshalJSON() = %s, want evidence sorted by key", data)
+	}
+
+	filename := filepath.Join(t.TempDir(), "evidence.json")
+
+	err = ioutil.WriteFile(filename, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadEvidenceBundle(filename)
+	if err != nil {
+		t.Fatalf("LoadEvidenceBundle() error = %v", err)
+	}
+
+	if loaded.Len() != 2 || loaded.Get("btc/main/tx/found") == nil || loaded.Get("btc/main/tx/missing") != nil {
+		t.Errorf("LoadEvidenceBundle() loaded %d evidence", loaded.Len())
+	}
+
+	err = ioutil.WriteFile(filename, []byte(`{"evidence": [{"url": "https://anchor"}]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadEvidenceBundle(filename)
+	if err == nil {
+		t.Errorf("LoadEvidenceBundle() error = nil, want an error for evidence without key")
+	}
+}
+
+func Test_verifyOffline(t *testing.T) {
+	var (
+		// unreachable endpoints, which must not be used offline
+		btcEp    = Endpoint{URL: "http://127.0.0.1:1/", Network: "main", Provider: ProviderEsplora, MinConfirmations: int64Ptr(6)}
+		hederaEp = Endpoint{URL: "http://127.0.0.1:1/", Network: "testnet"}
+		ethEp    = Endpoint{URL: "http://127.0.0.1:1/", Network: "mainnet"}
+		uri      = "http://127.0.0.1:1/eth/0x01"
+		txID     = testTxID(t, false)
+		// the transaction whose confirmations are not supplied
+		unconfirmableTxID = testTxID(t, true)
+		header, blockHash = testBlockHeader(t)
+		recipient         = common.HexToAddress("0x2f3ca2a1d6de4a3b2ef9c4e3e8dd5ff35b8e1b7a")
+		ethHeader         = &types.Header{Number: big.NewInt(100), Time: 1516080000}
+		signed, _, _      = newTestEthTx(t, recipient, types.ReceiptStatusSuccessful, ethHeader)
+		other, _, _       = newTestEthTx(t, recipient, types.ReceiptStatusSuccessful, ethHeader)
+	)
+
+	b := NewEvidenceBundle()
+	b.Add(newTestEvidence(t, "btc/main/tx/"+txID, newRawEvidence(esploraTxFormat,
+		rawText(testRawTx(false)), json.RawMessage(`{"confirmed": true, "block_time": 1516080000}`))))
+	b.Add(newTestEvidence(t, "btc/main/confirmations/"+txID, &chainCount{Count: 6}))
+	b.Add(newTestEvidence(t, "btc/main/tx/"+unconfirmableTxID, newRawEvidence(esploraTxFormat,
+		rawText(testRawTx(true)), json.RawMessage(`{"confirmed": false}`))))
+	b.Add(newTestEvidence(t, "hedera/testnet/tx/0.0.1234@1568262436.123456789", newRawEvidence(hederaTxFormat,
+		json.RawMessage(fmt.Sprintf(`{"transactions": [{"consensus_timestamp": "1568262446.120000000", "memo_base64": "%s", "result": "%s"}]}`,
+			base64.StdEncoding.EncodeToString([]byte(testOpReturnValue)), hederaResultSuccess)))))
+	b.Add(newTestEvidence(t, uriEvidenceKey(uri), testOpReturnValue))
+	b.Add(newTestEvidence(t, "btc/main/block/503275", newRawEvidence(esploraBlockFormat, rawText(blockHash), rawText(header))))
+	b.Add(newTestEvidence(t, "btc/main/tip", &chainCount{Count: 503280}))
+	b.Add(newTestEvidence(t, "eth/mainnet/tx/"+signed.Hash().Hex(), newTestEthEvidence(t, signed, types.ReceiptStatusSuccessful)))
+
+	// hand-edited evidence, which is rejected the same way as a provider returning it
+	b.Add(newTestEvidence(t, "btc/main/tx/"+testForgedTxID, newRawEvidence(esploraTxFormat,
+		rawText(testRawTx(false)), json.RawMessage(`{"confirmed": true, "block_time": 1516080000}`))))
+	b.Add(newTestEvidence(t, "btc/main/confirmations/"+testForgedTxID, &chainCount{Count: 6}))
+	b.Add(newTestEvidence(t, "btc/main/block/503276", newRawEvidence(esploraBlockFormat,
+		rawText(strings.Repeat("00", 32)), rawText(header))))
+	b.Add(newTestEvidence(t, "btc/main/block/503277", &btcBlock{MerkleRoot: testOpReturnValue}))
+	b.Add(newTestEvidence(t, "eth/mainnet/tx/"+other.Hash().Hex(), newTestEthEvidence(t, signed, types.ReceiptStatusSuccessful)))
+
+	tests := []struct {
+		name    string
+		verify  func(ctx context.Context, v *verifier) error
+		wantErr error
+	}{
+		{
+			"Verify Bitcoin transaction with confirmations",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBtcTxnData(ctx, Result{}, txID, testOpReturnValue, btcEp)
+			},
+			nil,
+		},
+		{
+			"Falsify Bitcoin transaction",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBtcTxnData(ctx, Result{}, txID, strings.Repeat("00", 32), btcEp)
+			},
+			status.ErrAnchorValueMismatch,
+		},
+		{
+			"Missing confirmations",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBtcTxnData(ctx, Result{}, unconfirmableTxID, testOpReturnValue, btcEp)
+			},
+			status.ErrEvidenceMissing,
+		},
+		{
+			"Missing Bitcoin transaction",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBtcTxnData(ctx, Result{}, "missing", testOpReturnValue, btcEp)
+			},
+			status.ErrEvidenceMissing,
+		},
+		{
+			"Forged Bitcoin transaction",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBtcTxnData(ctx, Result{}, testForgedTxID, testOpReturnValue, btcEp)
+			},
+			status.ErrAnchorUnreachable,
+		},
+		{
+			"Verify Bitcoin block",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBitcoinBlockMerkleRoot(ctx, Result{}, "503275", testOpReturnValue, btcEp)
+			},
+			nil,
+		},
+		{
+			"Forged Bitcoin block header",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBitcoinBlockMerkleRoot(ctx, Result{}, "503276", testOpReturnValue, btcEp)
+			},
+			status.ErrAnchorUnreachable,
+		},
+		{
+			"Bitcoin block without a header",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyBitcoinBlockMerkleRoot(ctx, Result{}, "503277", testOpReturnValue, btcEp)
+			},
+			status.ErrAnchorUnreachable,
+		},
+		{
+			"Verify Ethereum transaction",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyEthTxnData(ctx, Result{}, signed.Hash().Hex(), "abcd", ethEp)
+			},
+			nil,
+		},
+		{
+			"Forged Ethereum transaction",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyEthTxnData(ctx, Result{}, other.Hash().Hex(), "abcd", ethEp)
+			},
+			status.ErrAnchorUnreachable,
+		},
+		{
+			"Verify Hedera transaction",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyHederaTxnData(ctx, Result{}, "0.0.1234@1568262436.123456789", testOpReturnValue, hederaEp)
+			},
+			nil,
+		},
+		{
+			"Verify anchor URI",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyAnchorURIs(ctx, Result{}, []string{uri}, testOpReturnValue)
+			},
+			nil,
+		},
+		{
+			"Missing anchor URI",
+			func(ctx context.Context, v *verifier) error {
+				return v.verifyAnchorURIs(ctx, Result{}, []string{uri + "2"}, testOpReturnValue)
+			},
+			status.ErrEvidenceMissing,
+		},
+		{
+			"Registered verifier lookup",
+			func(ctx context.Context, v *verifier) error {
+				var value string
+
+				err := (&Checker{v}).Lookup(ctx, uriEvidenceKey(uri), uri, &value, func() (interface{}, error) {
+					return nil, errors.New("should not fetch")
+				})
+				if err == nil && value != testOpReturnValue {
+					return fmt.Errorf("Lookup() value = %s, want %s", value, testOpReturnValue)
+				}
+
+				return err
+			},
+			nil,
+		},
+		{
+			"Missing registered verifier lookup",
+			func(ctx context.Context, v *verifier) error {
+				var value string
+
+				return (&Checker{v}).Lookup(ctx, uriEvidenceKey(uri+"2"), uri+"2", &value, func() (interface{}, error) {
+					return testOpReturnValue, nil
+				})
+			},
+			status.ErrEvidenceMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, false)
+			v.cfg.Offline = true
+			v.cfg.Evidence = b
+
+			err := tt.verify(context.Background(), v)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, status.ErrEvidenceMissing) &&
+				status.Of(err).Status != status.VerificationStatusUnverifiable {
+				t.Errorf("verify() status = %v, want unverifiable", status.Of(err).Status)
+			}
+		})
+	}
+}
+
+func Test_recordEvidence(t *testing.T) {
+	var (
+		esplora    = newTestEsplora(t)
+		mirrorNode = newTestMirrorNode(t)
+		btcEp      = Endpoint{URL: esplora.URL + "/", Network: "main", Provider: ProviderEsplora, MinConfirmations: int64Ptr(6)}
+		hederaEp   = Endpoint{URL: mirrorNode.URL + "/", Network: "testnet"}
+		record     = NewEvidenceBundle()
+	)
+
+	txID := testTxID(t, false)
+
+	verify := func(v *verifier) error {
+		ctx := context.Background()
+
+		err := v.verifyBtcTxnData(ctx, Result{}, txID, testOpReturnValue, btcEp)
+		if err != nil {
+			return err
+		}
+
+		err = v.verifyBitcoinBlockMerkleRoot(ctx, Result{}, "503275", testOpReturnValue, btcEp)
+		if err != nil {
+			return err
+		}
+
+		return v.verifyHederaTxnData(ctx, Result{}, "0.0.1234@1568262436.123456789", testOpReturnValue, hederaEp)
+	}
+
+	v := newTestVerifier(t, true)
+	v.cfg.Record = record
+
+	err := verify(v)
+	if err != nil {
+		t.Fatalf("verify() error = %v", err)
+	}
+
+	// the transaction, its confirmations, the block, the chain tip and the Hedera transaction
+	if record.Len() != 5 {
+		t.Fatalf("verify() recorded %d evidence, want 5", record.Len())
+	}
+
+	if e := record.Get("btc/main/tx/" + txID); e == nil || e.URL != esplora.URL+"/tx/"+txID || e.FetchedAt.IsZero() {
+		t.Errorf("verify() recorded %+v", e)
+	}
+
+	esplora.Close()
+	mirrorNode.Close()
+
+	for _, name := range []string{"evidence.json", "evidence.zip"} {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), name)
+
+			err := record.WriteFile(filename)
+			if err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			b, err := LoadEvidenceBundle(filename)
+			if err != nil {
+				t.Fatalf("LoadEvidenceBundle() error = %v", err)
+			}
+
+			v := newTestVerifier(t, true)
+			v.cfg.Offline = true
+			v.cfg.Evidence = b
+
+			err = verify(v)
+			if err != nil {
+				t.Errorf("verify() offline error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,549 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T21:52:40+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T21:52:40+11:00
+ */
+
+package anchor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const (
+	// btcRetargetInterval is the number of blocks between Bitcoin difficulty retargets
+	btcRetargetInterval = 2016
+	// btcTargetTimespan is the expected duration of a retarget interval in seconds
+	btcTargetTimespan = 14 * 24 * 60 * 60
+	// btcTargetSpacing is the expected duration between blocks in seconds
+	btcTargetSpacing = 10 * 60
+	// btcMedianTimeSpan is the number of previous blocks whose median time a block must be after
+	btcMedianTimeSpan = 11
+	// btcMaxFutureBlockTime is how far in the future a block time can be
+	btcMaxFutureBlockTime = 2 * time.Hour
+)
+
+// btcChainParams are the consensus parameters of a Bitcoin network used to check its headers
+type btcChainParams struct {
+	network string
+	// powLimit is the easiest target
+	powLimit *big.Int
+	// minDifficultyBlocks indicates whether a block can have the easiest target when it is more
+	// than 20 minutes after its previous block, as on testnet
+	minDifficultyBlocks bool
+	// noRetargeting indicates whether the target stays the same at retarget heights, as on regtest
+	noRetargeting bool
+	// checkpoints are the trusted blocks keyed by height. They must be at retarget heights, so the
+	// targets of the blocks after them can be checked
+	checkpoints map[int64]btcCheckpoint
+	// minimumChainWork is the least total work of a chain up to its last header. It is far below
+	// the chain work of the network when the first ProvenDB anchors were made, but far above the
+	// work that a single miner can do, so the headers cannot be forged cheaply on top of an early
+	// checkpoint, such as the genesis block, by keeping the target at the limit
+	minimumChainWork *big.Int
+}
+
+// btcCheckpoint is a trusted block of a Bitcoin network
+type btcCheckpoint struct {
+	// hash is the block hash in the display byte order
+	hash string
+	// chainWork is the total work of the chain up to and including the block, as returned in the
+	// `chainwork` of `getblockheader`
+	chainWork *big.Int
+}
+
+var btcChains = []*btcChainParams{
+	{
+		network:  "main",
+		powLimit: mustParseUint256("00000000ffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+		checkpoints: map[int64]btcCheckpoint{
+			0: {
+				"000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+				mustParseUint256("0000000000000000000000000000000000000000000000000000000100010001"),
+			},
+		},
+		// 2^80, while the chain work is about 2^88 at height 500000 in 2017
+		minimumChainWork: mustParseUint256("0000000000000000000000000000000000000000000100000000000000000000"),
+	},
+	{
+		network:             "test3",
+		powLimit:            mustParseUint256("00000000ffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+		minDifficultyBlocks: true,
+		checkpoints: map[int64]btcCheckpoint{
+			0: {
+				"000000000933ea01ad0ee984209779baaec3ced90fa3f408719526f8d77f4943",
+				mustParseUint256("0000000000000000000000000000000000000000000000000000000100010001"),
+			},
+		},
+		// 2^68, while the chain work is about 2^71 at height 1280000 in 2018
+		minimumChainWork: mustParseUint256("0000000000000000000000000000000000000000000000100000000000000000"),
+	},
+	{
+		network:             "regtest",
+		powLimit:            mustParseUint256("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+		minDifficultyBlocks: true,
+		noRetargeting:       true,
+		checkpoints: map[int64]btcCheckpoint{
+			0: {
+				"0f9188f13cb7b2c71f2a335e3a4fc328bf5beb436012afca590b1a11466e2206",
+				big.NewInt(2),
+			},
+		},
+		// a local network, whose blocks can be mined by anyone
+		minimumChainWork: big.NewInt(0),
+	},
+}
+
+// mustParseUint256 parses the given 256-bit number in hex, such as a target or a chain work
+func mustParseUint256(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid 256-bit number " + s)
+	}
+
+	return n
+}
+
+// HeaderChain is a chain of Bitcoin block headers loaded from a file, which is used to verify the
+// Bitcoin block merkle roots offline using simplified payment verification (SPV)
+type HeaderChain struct {
+	// Filename is where the headers are loaded from
+	Filename string
+	// StartHeight is the height of the first header
+	StartHeight int64
+	// Network is the Bitcoin network of the headers, such as `main` or `test3`, which is detected
+	// from the checkpoint that the headers pass through
+	Network string
+	// ChainWork is the total work of the chain up to the last header
+	ChainWork *big.Int
+	headers   [][]byte
+}
+
+// LoadHeaderChain loads a chain of raw 80-byte Bitcoin block headers, such as the ones returned by
+// `getblockheader HASH false`, from the given file. The first header is at the given height. The
+// headers must pass through a builtin checkpoint of a known network, such as its genesis block,
+// which commits to the headers before it. Every header after the checkpoint must link to the
+// previous header, have the target required by the difficulty rules of the network, have a valid
+// proof-of-work for the target, and have a time after the median time of its previous 11 headers
+// and no more than 2 hours in the future. The chain must have at least the minimum chain work of
+// the network, which is counted from the checkpoint
+func LoadHeaderChain(filename string, startHeight int64) (*HeaderChain, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load Bitcoin headers: %s", err)
+	}
+
+	if len(data) == 0 || len(data)%btcHeaderSize != 0 {
+		return nil, fmt.Errorf("cannot load Bitcoin headers from `%s`: size %d is not a multiple of %d",
+			filename, len(data), btcHeaderSize)
+	}
+
+	if startHeight < 0 {
+		return nil, fmt.Errorf("invalid start height %d of Bitcoin headers", startHeight)
+	}
+
+	c := &HeaderChain{
+		Filename:    filename,
+		StartHeight: startHeight,
+	}
+
+	var (
+		prevHash [sha256.Size]byte
+		hashes   [][sha256.Size]byte
+	)
+
+	for i := 0; i < len(data); i += btcHeaderSize {
+		header := data[i : i+btcHeaderSize]
+		height := startHeight + int64(len(c.headers))
+		hash := sha256d(header)
+
+		if len(c.headers) > 0 && !bytes.Equal(header[4:36], prevHash[:]) {
+			return nil, fmt.Errorf("Bitcoin header at height %d in `%s` doesn't link to its previous header",
+				height, filename)
+		}
+
+		c.headers = append(c.headers, header)
+		hashes = append(hashes, hash)
+		prevHash = hash
+	}
+
+	params, checkpoint := c.findCheckpoint(hashes)
+	if params == nil {
+		return nil, fmt.Errorf("Bitcoin headers in `%s` don't pass through a checkpoint of a known network",
+			filename)
+	}
+
+	c.Network = params.network
+	c.ChainWork = new(big.Int).Set(params.checkpoints[checkpoint].chainWork)
+
+	now := time.Now()
+
+	for i, header := range c.headers {
+		height := startHeight + int64(i)
+
+		// the headers up to the checkpoint are committed to by the checkpoint
+		if height <= checkpoint {
+			continue
+		}
+
+		err := c.checkTarget(params, height)
+		if err == nil {
+			err = checkProofOfWork(header, hashes[i])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Bitcoin header at height %d in `%s` has invalid proof-of-work: %s",
+				height, filename, err)
+		}
+
+		err = c.checkTime(height, now)
+		if err != nil {
+			return nil, fmt.Errorf("Bitcoin header at height %d in `%s` has invalid time: %s",
+				height, filename, err)
+		}
+
+		work, err := btcWork(btcHeaderBits(header))
+		if err != nil {
+			return nil, err
+		}
+
+		c.ChainWork.Add(c.ChainWork, work)
+	}
+
+	if c.ChainWork.Cmp(params.minimumChainWork) < 0 {
+		return nil, fmt.Errorf("Bitcoin headers in `%s` have chain work %x, but network `%s` requires at least %x",
+			filename, c.ChainWork, params.network, params.minimumChainWork)
+	}
+
+	return c, nil
+}
+
+// findCheckpoint finds the network whose checkpoints the headers with the given hashes pass through
+// without a mismatch, and returns the height of the first checkpoint passed through
+func (c *HeaderChain) findCheckpoint(hashes [][sha256.Size]byte) (*btcChainParams, int64) {
+	for _, p := range btcChains {
+		var (
+			checkpoint int64 = -1
+			matched          = true
+		)
+
+		for height, cp := range p.checkpoints {
+			if height < c.StartHeight || height > c.TipHeight() {
+				continue
+			}
+
+			if hex.EncodeToString(reverseBytes(hashes[height-c.StartHeight][:])) != cp.hash {
+				matched = false
+				break
+			}
+
+			if checkpoint < 0 || height < checkpoint {
+				checkpoint = height
+			}
+		}
+
+		if matched && checkpoint >= 0 {
+			return p, checkpoint
+		}
+	}
+
+	return nil, 0
+}
+
+// checkTarget checks the target bits of the header at the given height, which must follow the
+// difficulty rules of the given network
+func (c *HeaderChain) checkTarget(p *btcChainParams, height int64) error {
+	header, err := c.header(height)
+	if err != nil {
+		return err
+	}
+
+	bits := btcHeaderBits(header)
+
+	target, err := btcTarget(bits)
+	if err != nil {
+		return err
+	}
+
+	if target.Cmp(p.powLimit) > 0 {
+		return fmt.Errorf("target bits %08x are easier than the limit of network `%s`", bits, p.network)
+	}
+
+	want, err := c.nextTargetBits(p, height)
+	if err != nil {
+		return err
+	}
+
+	if bits != want {
+		return fmt.Errorf("target bits %08x don't match the required %08x", bits, want)
+	}
+
+	return nil
+}
+
+// checkTime checks the time of the header at the given height, which must be after the median time
+// of its previous headers in the chain, up to 11 of them, and no more than 2 hours after the given
+// current time
+func (c *HeaderChain) checkTime(height int64, now time.Time) error {
+	header, err := c.header(height)
+	if err != nil {
+		return err
+	}
+
+	blockTime := btcHeaderTime(header)
+
+	if blockTime.After(now.Add(btcMaxFutureBlockTime)) {
+		return fmt.Errorf("%s is more than %s in the future", blockTime.Format(time.RFC3339),
+			btcMaxFutureBlockTime)
+	}
+
+	var times []int64
+
+	for h := height - 1; h >= c.StartHeight && h >= height-btcMedianTimeSpan; h-- {
+		prev, err := c.header(h)
+		if err != nil {
+			return err
+		}
+
+		times = append(times, btcHeaderTime(prev).Unix())
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	if median := times[len(times)/2]; blockTime.Unix() <= median {
+		return fmt.Errorf("%s is not after the median time %s of the previous blocks",
+			blockTime.Format(time.RFC3339), time.Unix(median, 0).UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// nextTargetBits returns the target bits required for the block at the given height, whose
+// previous headers must be in the chain
+func (c *HeaderChain) nextTargetBits(p *btcChainParams, height int64) (uint32, error) {
+	header, err := c.header(height)
+	if err != nil {
+		return 0, err
+	}
+
+	prev, err := c.header(height - 1)
+	if err != nil {
+		return 0, err
+	}
+
+	if height%btcRetargetInterval != 0 {
+		if !p.minDifficultyBlocks {
+			return btcHeaderBits(prev), nil
+		}
+
+		powLimitBits := btcCompact(p.powLimit)
+
+		if btcHeaderTime(header).Unix() > btcHeaderTime(prev).Unix()+2*btcTargetSpacing {
+			return powLimitBits, nil
+		}
+
+		// the target of the last block that isn't at the easiest target due to the delay
+		h := height - 1
+		for h > c.StartHeight && h%btcRetargetInterval != 0 {
+			b, err := c.header(h)
+			if err != nil {
+				return 0, err
+			}
+
+			if btcHeaderBits(b) != powLimitBits {
+				break
+			}
+
+			h--
+		}
+
+		b, err := c.header(h)
+		if err != nil {
+			return 0, err
+		}
+
+		return btcHeaderBits(b), nil
+	}
+
+	if p.noRetargeting {
+		return btcHeaderBits(prev), nil
+	}
+
+	first, err := c.header(height - btcRetargetInterval)
+	if err != nil {
+		return 0, err
+	}
+
+	return btcRetarget(btcHeaderBits(prev), btcHeaderTime(prev).Unix()-btcHeaderTime(first).Unix(),
+		p.powLimit)
+}
+
+// btcRetarget returns the target bits adjusted from the given ones by the given duration of the
+// last retarget interval in seconds
+func btcRetarget(bits uint32, timespan int64, powLimit *big.Int) (uint32, error) {
+	if timespan < btcTargetTimespan/4 {
+		timespan = btcTargetTimespan / 4
+	} else if timespan > btcTargetTimespan*4 {
+		timespan = btcTargetTimespan * 4
+	}
+
+	target, err := btcTarget(bits)
+	if err != nil {
+		return 0, err
+	}
+
+	target.Mul(target, big.NewInt(timespan))
+	target.Div(target, big.NewInt(btcTargetTimespan))
+
+	if target.Cmp(powLimit) > 0 {
+		target = powLimit
+	}
+
+	return btcCompact(target), nil
+}
+
+// TipHeight returns the height of the last header
+func (c *HeaderChain) TipHeight() int64 {
+	return c.StartHeight + int64(len(c.headers)) - 1
+}
+
+// Confirmations returns the number of confirmations of the block at the given height, which counts
+// the block itself and the blocks on top of it in the chain
+func (c *HeaderChain) Confirmations(height int64) int64 {
+	return confirmations(c.TipHeight(), height)
+}
+
+// MerkleRoot returns the merkle root of the block at the given height in the display byte order
+func (c *HeaderChain) MerkleRoot(height int64) (string, error) {
+	header, err := c.header(height)
+	if err != nil {
+		return "", err
+	}
+
+	return btcHeaderMerkleRoot(header), nil
+}
+
+// BlockTime returns the time of the block at the given height
+func (c *HeaderChain) BlockTime(height int64) (time.Time, error) {
+	header, err := c.header(height)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return btcHeaderTime(header), nil
+}
+
+func (c *HeaderChain) header(height int64) ([]byte, error) {
+	if height < c.StartHeight || height > c.TipHeight() {
+		return nil, fmt.Errorf("Bitcoin block height %d is not in `%s`, which has heights %d to %d",
+			height, c.Filename, c.StartHeight, c.TipHeight())
+	}
+
+	return c.headers[height-c.StartHeight], nil
+}
+
+// uri returns the location of the header at the given height
+func (c *HeaderChain) uri(height string) string {
+	return "file://" + c.Filename + "#" + height
+}
+
+func sha256d(data []byte) [sha256.Size]byte {
+	h := sha256.Sum256(data)
+	return sha256.Sum256(h[:])
+}
+
+// btcHeaderBits returns the target bits of the given 80-byte block header
+func btcHeaderBits(header []byte) uint32 {
+	return binary.LittleEndian.Uint32(header[72:76])
+}
+
+// btcTarget decodes the given target bits
+func btcTarget(bits uint32) (*big.Int, error) {
+	exponent := uint(bits >> 24)
+	mantissa := int64(bits & 0x007fffff)
+
+	if mantissa == 0 || bits&0x00800000 != 0 {
+		return nil, fmt.Errorf("invalid target bits %08x", bits)
+	}
+
+	target := big.NewInt(mantissa)
+	if exponent <= 3 {
+		target.Rsh(target, 8*(3-exponent))
+	} else {
+		target.Lsh(target, 8*(exponent-3))
+	}
+
+	return target, nil
+}
+
+// btcWork returns the expected number of hashes to find a block with the given target bits
+func btcWork(bits uint32) (*big.Int, error) {
+	target, err := btcTarget(bits)
+	if err != nil {
+		return nil, err
+	}
+
+	work := new(big.Int).Lsh(big.NewInt(1), 256)
+	return work.Div(work, target.Add(target, big.NewInt(1))), nil
+}
+
+// btcCompact encodes the given target as target bits, which truncates it to 3 significant bytes
+func btcCompact(target *big.Int) uint32 {
+	size := uint((target.BitLen() + 7) / 8)
+
+	var mantissa uint64
+	if size <= 3 {
+		mantissa = target.Uint64() << (8 * (3 - size))
+	} else {
+		mantissa = new(big.Int).Rsh(target, 8*(size-3)).Uint64()
+	}
+
+	// the sign bit must be clear
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		size++
+	}
+
+	return uint32(mantissa) | uint32(size)<<24
+}
+
+// checkProofOfWork checks the given header hash is not above the target encoded in the header bits
+func checkProofOfWork(header []byte, hash [sha256.Size]byte) error {
+	bits := btcHeaderBits(header)
+
+	target, err := btcTarget(bits)
+	if err != nil {
+		return err
+	}
+
+	if new(big.Int).SetBytes(reverseBytes(hash[:])).Cmp(target) > 0 {
+		return fmt.Errorf("hash is above the target of bits %08x", bits)
+	}
+
+	return nil
+}
@@ -0,0 +1,484 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T22:10:03+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T22:10:03+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+const (
+	// easyBits is the regtest target bits, which is met by about half of the hashes
+	easyBits = 0x207fffff
+	// testRegtestGenesis is the regtest genesis block header
+	testRegtestGenesis = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e" +
+		"67768f617fc81bc3888a51323a9fb8aa4b1e5e4adae5494dffff7f2002000000"
+	// testMainnetGenesis is the mainnet genesis block header
+	testMainnetGenesis = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e" +
+		"67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+)
+
+// mineTestHeader mines a header with the given merkle root in the display byte order on top of the
+// block of the given hash. Only the targets of exponent 0x20 are mined
+func mineTestHeader(t *testing.T, prevHash [32]byte, root string, blockTime, bits uint32) []byte {
+	header := make([]byte, btcHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], 0x20000000)
+	copy(header[4:36], prevHash[:])
+	copy(header[36:68], reverseBytes(mustDecodeHex(t, root)))
+	binary.LittleEndian.PutUint32(header[68:72], blockTime)
+	binary.LittleEndian.PutUint32(header[72:76], bits)
+
+	for nonce := uint32(0); ; nonce++ {
+		binary.LittleEndian.PutUint32(header[76:80], nonce)
+
+		if bits>>24 != 0x20 || checkProofOfWork(header, sha256d(header)) == nil {
+			return header
+		}
+	}
+}
+
+// mineTestHeaders mines a chain of headers with the given merkle roots on top of the given header,
+// which are 10 minutes apart
+func mineTestHeaders(t *testing.T, prev string, bits uint32, roots ...string) []byte {
+	return mineTestHeadersAt(t, prev, bits, func(i int) uint32 {
+		return 1555555555 + uint32(i)*btcTargetSpacing
+	}, roots...)
+}
+
+// mineTestHeadersAt is like `mineTestHeaders`, but the time of each header is given by its index
+func mineTestHeadersAt(t *testing.T, prev string, bits uint32, blockTime func(i int) uint32,
+	roots ...string) []byte {
+	data := mustDecodeHex(t, prev)
+	prevHash := sha256d(data)
+
+	for i, root := range roots {
+		header := mineTestHeader(t, prevHash, root, blockTime(i), bits)
+		prevHash = sha256d(header)
+		data = append(data, header...)
+	}
+
+	return data
+}
+
+func writeTestHeaders(t *testing.T, data []byte) string {
+	filename := filepath.Join(t.TempDir(), "headers.bin")
+
+	err := ioutil.WriteFile(filename, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+func TestLoadHeaderChain(t *testing.T) {
+	roots := []string{
+		strings.Repeat("01", 32),
+		testOpReturnValue,
+		strings.Repeat("02", 32),
+	}
+	valid := mineTestHeaders(t, testRegtestGenesis, easyBits, roots...)
+
+	unlinked := append([]byte{}, valid...)
+	unlinked[btcHeaderSize*3+4] ^= 0xff
+
+	// a tip header whose hash is above its target
+	unmined := append([]byte{}, valid...)
+	tip := unmined[btcHeaderSize*3:]
+	for checkProofOfWork(tip, sha256d(tip)) == nil {
+		tip[76]++
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{
+			"Valid headers",
+			valid,
+			"",
+		},
+		{
+			"Time not after the median time",
+			mineTestHeadersAt(t, testRegtestGenesis, easyBits, func(i int) uint32 { return 1555555555 }, roots...),
+			"not after the median time",
+		},
+		{
+			"Time in the future",
+			mineTestHeadersAt(t, testRegtestGenesis, easyBits, func(i int) uint32 {
+				return uint32(time.Now().Add(3*time.Hour).Unix()) + uint32(i)
+			}, roots...),
+			"in the future",
+		},
+		{
+			"Low chain work from the genesis block",
+			mustDecodeHex(t, testMainnetGenesis),
+			"network `main` requires at least",
+		},
+		{
+			"Unlinked header",
+			unlinked,
+			"doesn't link to its previous header",
+		},
+		{
+			"Insufficient proof-of-work",
+			unmined,
+			"hash is above the target",
+		},
+		{
+			"Unexpected target",
+			mineTestHeaders(t, testRegtestGenesis, 0x207ffffe, roots...),
+			"don't match the required 207fffff",
+		},
+		{
+			"Target easier than the limit",
+			mineTestHeaders(t, testMainnetGenesis, easyBits, roots...),
+			"easier than the limit of network `main`",
+		},
+		{
+			"No checkpoint",
+			valid[btcHeaderSize:],
+			"don't pass through a checkpoint",
+		},
+		{
+			"Truncated header",
+			valid[:btcHeaderSize*2+1],
+			"is not a multiple of 80",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := LoadHeaderChain(writeTestHeaders(t, tt.data), 0)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadHeaderChain() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if chain.Network != "regtest" {
+				t.Errorf("Network = %s, want regtest", chain.Network)
+			}
+
+			// the genesis block and the mined blocks each have the work of 2 hashes
+			if chain.ChainWork.Int64() != 8 {
+				t.Errorf("ChainWork = %s, want 8", chain.ChainWork)
+			}
+
+			if chain.TipHeight() != 3 || chain.Confirmations(2) != 2 {
+				t.Errorf("TipHeight() = %d, Confirmations() = %d", chain.TipHeight(), chain.Confirmations(2))
+			}
+
+			if root, err := chain.MerkleRoot(2); err != nil || root != testOpReturnValue {
+				t.Errorf("MerkleRoot() = %s, %v, want %s", root, err, testOpReturnValue)
+			}
+
+			if _, err := chain.MerkleRoot(4); err == nil {
+				t.Errorf("MerkleRoot() error = nil for a height out of the chain")
+			}
+		})
+	}
+}
+
+func TestLoadHeaderChainMinimumChainWork(t *testing.T) {
+	regtest := btcChains[2]
+	defer func(w *big.Int) { regtest.minimumChainWork = w }(regtest.minimumChainWork)
+
+	// a chain of blocks at the easiest target from the genesis block, as an attacker can mine
+	filename := writeTestHeaders(t, mineTestHeaders(t, testRegtestGenesis, easyBits,
+		strings.Repeat("01", 32), testOpReturnValue))
+
+	tests := []struct {
+		name             string
+		minimumChainWork int64
+		wantErr          bool
+	}{
+		{
+			"Enough chain work",
+			6,
+			false,
+		},
+		{
+			"Too little chain work",
+			7,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regtest.minimumChainWork = big.NewInt(tt.minimumChainWork)
+
+			_, err := LoadHeaderChain(filename, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadHeaderChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHeaderChainCheckTarget(t *testing.T) {
+	// a network that retargets with the regtest limit, so the headers are easy to mine
+	params := &btcChainParams{
+		network:  "test",
+		powLimit: mustParseUint256("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+	}
+
+	var (
+		chain    = &HeaderChain{}
+		prevHash [32]byte
+	)
+
+	// the blocks of the first interval are twice as fast as expected
+	for i := uint32(0); i < btcRetargetInterval; i++ {
+		header := mineTestHeader(t, prevHash, testOpReturnValue, 1555555555+i*btcTargetSpacing/2, easyBits)
+		prevHash = sha256d(header)
+		chain.headers = append(chain.headers, header)
+	}
+
+	retargeted, err := btcRetarget(easyBits, (btcRetargetInterval-1)*btcTargetSpacing/2, params.powLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		bits    uint32
+		wantErr bool
+	}{
+		{
+			"Retargeted",
+			retargeted,
+			false,
+		},
+		{
+			"Not retargeted",
+			easyBits,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *chain
+			c.headers = append(c.headers[:btcRetargetInterval:btcRetargetInterval],
+				mineTestHeader(t, prevHash, testOpReturnValue, 1555555555+btcTargetTimespan/2, tt.bits))
+
+			if err := c.checkTarget(params, btcRetargetInterval); (err != nil) != tt.wantErr {
+				t.Errorf("checkTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// the target only changes at retarget heights
+			if err := c.checkTarget(params, btcRetargetInterval-1); err != nil {
+				t.Errorf("checkTarget() error = %v before the retarget height", err)
+			}
+		})
+	}
+}
+
+func Test_btcRetarget(t *testing.T) {
+	powLimit := btcChains[0].powLimit
+
+	tests := []struct {
+		name     string
+		timespan int64
+		want     uint32
+	}{
+		{
+			"Expected timespan",
+			btcTargetTimespan,
+			0x1d00ffff,
+		},
+		{
+			"Half timespan",
+			btcTargetTimespan / 2,
+			0x1c7fff80,
+		},
+		{
+			"Timespan clamped to a quarter",
+			1,
+			0x1c3fffc0,
+		},
+		{
+			"Target capped at the limit",
+			btcTargetTimespan * 2,
+			0x1d00ffff,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := btcRetarget(0x1d00ffff, tt.timespan, powLimit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("btcRetarget() = %08x, want %08x", got, tt.want)
+			}
+		})
+	}
+
+	// the sign bit of the mantissa must be clear
+	if got := btcCompact(big.NewInt(0x80)); got != 0x02008000 {
+		t.Errorf("btcCompact() = %08x, want 02008000", got)
+	}
+}
+
+func Test_verifyBitcoinBranchOffline(t *testing.T) {
+	chain, err := LoadHeaderChain(writeTestHeaders(t, mineTestHeaders(t, testRegtestGenesis, easyBits,
+		testOpReturnValue, strings.Repeat("01", 32))), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	branch := &model.EvaluatedBranch{
+		Label:         btcAnchorBranch,
+		BtcTxID:       "ba3c8c3e547ed73471c28a69659373f3f0a3b726aab31cdecd14513d9c581f1e",
+		OpReturnValue: hex.EncodeToString([]byte("unchecked")),
+		Anchors: []model.EvaluatedAnchor{
+			{
+				Type:     "btc",
+				AnchorID: "1",
+				URIs: []string{
+					"https://a.chainpoint.org/calendar/985814/data",
+				},
+				ExpectedValue: testOpReturnValue,
+				Op:            40,
+			},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		network          string
+		minConfirmations int64
+		expectedValue    string
+		wantErr          error
+	}{
+		{
+			"Verify merkle root",
+			"regtest",
+			2,
+			testOpReturnValue,
+			nil,
+		},
+		{
+			"Falsify merkle root",
+			"regtest",
+			2,
+			strings.Repeat("00", 32),
+			status.ErrAnchorValueMismatch,
+		},
+		{
+			"Insufficient confirmations",
+			"regtest",
+			6,
+			testOpReturnValue,
+			status.ErrInsufficientConfirmations,
+		},
+		{
+			"Headers of another network",
+			"main",
+			2,
+			testOpReturnValue,
+			status.ErrAnchorUnreachable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, true)
+			v.cfg.BtcHeaders = chain
+			v.cfg.SetEndpoint(btcAnchorBranchType, Endpoint{Network: tt.network,
+				MinConfirmations: &tt.minConfirmations})
+
+			b := *branch
+			b.Anchors = append([]model.EvaluatedAnchor{}, branch.Anchors...)
+			b.Anchors[0].ExpectedValue = tt.expectedValue
+
+			err := v.verifyBitcoinBranch(context.Background(), []string{btcAnchorBranch}, &b)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyBitcoinBranch() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			// the Calendar URI and the transaction are skipped
+			if len(v.results) != 3 {
+				t.Fatalf("verifyBitcoinBranch() recorded %d results, want 3", len(v.results))
+			}
+
+			for _, r := range v.results {
+				if r.Check == CheckBtcBlockMerkleRoot {
+					if r.Skipped || r.ActualValue != testOpReturnValue || !strings.HasPrefix(r.URI, "file://") || r.AnchoredAt == nil {
+						t.Errorf("verifyBitcoinBranch() recorded %+v", r)
+					}
+				} else if !r.Skipped {
+					t.Errorf("verifyBitcoinBranch() recorded %+v, want skipped", r)
+				}
+			}
+		})
+	}
+
+	t.Run("Branch without Bitcoin anchor", func(t *testing.T) {
+		v := newTestVerifier(t, true)
+		v.cfg.BtcHeaders = chain
+		v.cfg.Offline = true
+
+		b := *branch
+		b.Anchors = []model.EvaluatedAnchor{
+			{Type: "cal", AnchorID: "985814", ExpectedValue: testOpReturnValue, Op: 40},
+		}
+
+		// no merkle root is verified against the headers, so the transaction must be looked up
+		err := v.verifyBitcoinBranch(context.Background(), []string{btcAnchorBranch}, &b)
+		if !errors.Is(err, status.ErrEvidenceMissing) {
+			t.Fatalf("verifyBitcoinBranch() error = %v, want %v", err, status.ErrEvidenceMissing)
+		}
+
+		for _, r := range v.results {
+			if r.Check == CheckBtcTxOpReturn && r.Skipped {
+				t.Errorf("verifyBitcoinBranch() recorded %+v, want not skipped", r)
+			}
+		}
+	})
+}
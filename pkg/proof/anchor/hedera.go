@@ -0,0 +1,147 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:52:44+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:52:44+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/httputil"
+)
+
+const (
+	// hederaResultSuccess is the result of a successful Hedera transaction
+	hederaResultSuccess = "SUCCESS"
+	// hederaTxFormat is the format of the raw evidence of a Hedera transaction, which is the mirror
+	// node transaction body
+	hederaTxFormat = "hedera/tx"
+)
+
+// hederaTx represents a Hedera transaction got from a mirror node
+type hederaTx struct {
+	// Memo is the decoded memo
+	Memo string `json:"memo"`
+	// Result is the transaction result, such as `SUCCESS`
+	Result string `json:"result"`
+	// ConsensusAt is the consensus timestamp
+	ConsensusAt time.Time `json:"consensusAt"`
+}
+
+// hederaTxURI returns the mirror node REST API location of the given transaction, whose ID can be
+// either in the SDK form of `0.0.1234@1568262436.123456789` or the mirror node form of
+// `0.0.1234-1568262436-123456789`, or a transaction hash
+func hederaTxURI(ep Endpoint, txID string) string {
+	if i := strings.Index(txID, "@"); i >= 0 {
+		txID = txID[:i] + "-" + strings.Replace(txID[i+1:], ".", "-", 1)
+	}
+
+	return strings.TrimSuffix(ep.resolvedURL(), "/") + "/api/v1/transactions/" + txID
+}
+
+// getHederaTx gets the raw evidence of the given transaction from the mirror node of the given
+// endpoint
+func getHederaTx(ctx context.Context, ep Endpoint, txID string) (*rawEvidence, error) {
+	var body json.RawMessage
+
+	err := httputil.UnmarshalHTTPGetJSON(ctx, hederaTxURI(ep, txID), &body)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRawEvidence(hederaTxFormat, body), nil
+}
+
+// parse parses the raw evidence of the given transaction
+func (t *hederaTx) parse(r *rawEvidence, txID string) error {
+	if r.Format != hederaTxFormat {
+		return r.errUnsupported()
+	}
+
+	var resp struct {
+		Transactions []struct {
+			ConsensusTimestamp string `json:"consensus_timestamp"`
+			MemoBase64         string `json:"memo_base64"`
+			Result             string `json:"result"`
+		} `json:"transactions"`
+	}
+
+	err := r.response(0, &resp)
+	if err != nil {
+		return err
+	}
+
+	// the first one is the user submitted transaction, which is followed by its child transactions
+	if len(resp.Transactions) == 0 {
+		return fmt.Errorf("Hedera transaction `%s` is not found", txID)
+	}
+
+	tx := resp.Transactions[0]
+
+	memo, err := base64.StdEncoding.DecodeString(tx.MemoBase64)
+	if err != nil {
+		return fmt.Errorf("invalid memo of Hedera transaction `%s`: %s", txID, err)
+	}
+
+	consensusAt, err := parseHederaTimestamp(tx.ConsensusTimestamp)
+	if err != nil {
+		return fmt.Errorf("invalid consensus timestamp of Hedera transaction `%s`: %s", txID, err)
+	}
+
+	*t = hederaTx{
+		Memo:        string(memo),
+		Result:      tx.Result,
+		ConsensusAt: consensusAt,
+	}
+
+	return nil
+}
+
+// parseHederaTimestamp parses a mirror node timestamp in the form of `SECONDS.NANOSECONDS`
+func parseHederaTimestamp(ts string) (time.Time, error) {
+	parts := strings.SplitN(ts, ".", 2)
+
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var nsec int64
+
+	if len(parts) == 2 {
+		frac := (parts[1] + "000000000")[:9]
+
+		nsec, err = strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Unix(sec, nsec).UTC(), nil
+}
@@ -0,0 +1,163 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:58:09+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:58:09+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+func newTestMirrorNode(t *testing.T) *httptest.Server {
+	txs := map[string]string{
+		"0.0.1234-1568262436-123456789": hederaResultSuccess,
+		"0.0.1234-1568262436-000000001": "INSUFFICIENT_TX_FEE",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, ok := txs[strings.TrimPrefix(r.URL.Path, "/api/v1/transactions/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"_status": map[string]interface{}{
+					"messages": []map[string]string{{"message": "Not found"}},
+				},
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"transactions": []map[string]interface{}{
+				{
+					"consensus_timestamp": "1568262446.120000000",
+					"memo_base64":         base64.StdEncoding.EncodeToString([]byte(testOpReturnValue)),
+					"name":                "CONSENSUSSUBMITMESSAGE",
+					"result":              result,
+				},
+			},
+		})
+	}))
+}
+
+func Test_verifyHederaTxnData(t *testing.T) {
+	ts := newTestMirrorNode(t)
+	defer ts.Close()
+
+	ep := Endpoint{URL: ts.URL + "/", Network: "testnet"}
+
+	tests := []struct {
+		name          string
+		txID          string
+		expectedValue string
+		wantErr       error
+	}{
+		{
+			"Verify transaction in SDK form",
+			"0.0.1234@1568262436.123456789",
+			testOpReturnValue,
+			nil,
+		},
+		{
+			"Verify transaction in mirror node form",
+			"0.0.1234-1568262436-123456789",
+			testOpReturnValue,
+			nil,
+		},
+		{
+			"Falsify memo",
+			"0.0.1234-1568262436-123456789",
+			strings.Repeat("00", 32),
+			status.ErrAnchorValueMismatch,
+		},
+		{
+			"Failed transaction",
+			"0.0.1234-1568262436-000000001",
+			testOpReturnValue,
+			status.ErrAnchorTxFailed,
+		},
+		{
+			"Missing transaction",
+			"0.0.1234-1568262436-000000002",
+			testOpReturnValue,
+			status.ErrAnchorUnreachable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, true)
+			err := v.verifyHederaTxnData(context.Background(), Result{}, tt.txID, tt.expectedValue, ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyHederaTxnData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			r := v.results[0]
+
+			if !strings.HasPrefix(r.URI, ts.URL+"/api/v1/transactions/0.0.1234-") {
+				t.Errorf("verifyHederaTxnData() recorded URI %s", r.URI)
+			}
+
+			want := time.Unix(1568262446, 120000000)
+
+			if tt.wantErr == nil && (r.AnchoredAt == nil || !r.AnchoredAt.Equal(want)) {
+				t.Errorf("verifyHederaTxnData() recorded anchoring time %v, want %v", r.AnchoredAt, want)
+			}
+		})
+	}
+}
+
+func Test_parseHederaTimestamp(t *testing.T) {
+	tests := []struct {
+		ts      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"1568262446.123456789", time.Unix(1568262446, 123456789), false},
+		{"1568262446.12", time.Unix(1568262446, 120000000), false},
+		{"1568262446", time.Unix(1568262446, 0), false},
+		{"", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ts, func(t *testing.T) {
+			got, err := parseHederaTimestamp(tt.ts)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHederaTimestamp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("parseHederaTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:59:52+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:59:52+11:00
+ */
+
+package anchor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+// ProviderResult represents the answer of one of the providers queried for a quorum
+type ProviderResult struct {
+	// URI is the location used by the provider to get the actual value
+	URI string `json:"uri"`
+	// ActualValue is the value got from the provider
+	ActualValue string `json:"actualValue,omitempty"`
+	// Error is the reason when the value cannot be got
+	Error string `json:"error,omitempty"`
+	// Agreed indicates whether the provider agrees with the value of the quorum
+	Agreed bool `json:"agreed"`
+}
+
+// providerAnswer is the answer of a provider to a lookup
+type providerAnswer struct {
+	// ep is the endpoint of the provider
+	ep Endpoint
+	// keySuffix is the suffix of the evidence keys of the provider
+	keySuffix string
+	// uri is the location used to get the value
+	uri string
+	// value is the looked up value, such as a `*btcTx`
+	value interface{}
+	// evidence is the evidence of the value, which is cached on disk once confirmed
+	evidence *Evidence
+	// actual is the actual value, such as the OP_RETURN of a Bitcoin transaction
+	actual string
+	// confirmations is the number of confirmations got from the provider when required, which
+	// fails with confirmationsErr
+	confirmations    int64
+	confirmationsErr error
+	// agreement is the key to be agreed on
+	agreement string
+	err       error
+}
+
+// agreer is a looked up value whose providers must agree on more than the actual value
+type agreer interface {
+	// agreementKey returns the key to be agreed on, which covers every field that the
+	// verification decides on. Informational fields, such as the block time, are left out, as
+	// providers report them differently
+	agreementKey() string
+}
+
+func (t *ethTx) agreementKey() string {
+	to := ""
+	if t.To != nil {
+		to = t.To.Hex()
+	}
+
+	return fmt.Sprintf("data=%s from=%s to=%s succeeded=%t block=%s canonical=%t", t.Data, t.From.Hex(), to,
+		t.Succeeded, t.BlockHash.Hex(), t.Canonical)
+}
+
+func (t *hederaTx) agreementKey() string {
+	return fmt.Sprintf("memo=%s result=%s", t.Memo, t.Result)
+}
+
+// agreementKey returns the key to be agreed on, which is the actual value unless the looked up
+// value is an `agreer`
+func (a *providerAnswer) agreementKey() string {
+	if ag, ok := a.value.(agreer); ok {
+		return ag.agreementKey()
+	}
+
+	return a.actual
+}
+
+// providers returns the endpoint followed by its alternates
+func (e Endpoint) providers() []Endpoint {
+	providers := []Endpoint{e}
+
+	for _, a := range e.Alternates {
+		if a.Network == "" {
+			a.Network = e.Network
+		}
+
+		providers = append(providers, a)
+	}
+
+	return providers
+}
+
+// quorum returns the minimum number of providers that must agree
+func (e Endpoint) quorum() int {
+	if e.Quorum != nil && *e.Quorum > 0 {
+		return int(*e.Quorum)
+	}
+
+	return (len(e.Alternates)+1)/2 + 1
+}
+
+// providerKey returns the suffix of the evidence key of the provider, so the evidence of different
+// providers is kept apart
+func (e Endpoint) providerKey() string {
+	key := "@" + RedactURL(e.URL)
+	if e.Provider != "" {
+		key = "@" + e.Provider + "+" + RedactURL(e.URL)
+	}
+
+	return key
+}
+
+// lookupQuorum looks up the actual value using the given function with the endpoint, as well as
+// each of its alternates when there are any, in which case the answers are recorded in the given
+// result. It returns the answer of the first provider that agrees with the quorum, whose
+// confirmations are the fewest among the agreeing providers, so a single provider cannot overstate
+// them. They fail when any of the agreeing providers fails to get them
+func (v *verifier) lookupQuorum(res *Result, ep Endpoint,
+	lookup func(p Endpoint, keySuffix string) providerAnswer) (providerAnswer, error) {
+	if len(ep.Alternates) == 0 && ep.quorum() <= 1 {
+		a := lookup(ep, "")
+		a.ep = ep
+		return a, a.err
+	}
+
+	var (
+		providers = ep.providers()
+		quorum    = ep.quorum()
+		answers   = make([]providerAnswer, len(providers))
+		wg        sync.WaitGroup
+	)
+
+	if quorum > len(providers) {
+		return providerAnswer{}, status.NewCodedError(
+			status.VerificationStatusUnverifiable,
+			status.CodeQuorumNotMet,
+			fmt.Errorf("quorum of %d cannot be met by %d providers", quorum, len(providers)),
+		)
+	}
+
+	for i, p := range providers {
+		wg.Add(1)
+
+		go func(i int, p Endpoint) {
+			defer wg.Done()
+			answers[i] = lookup(p, p.providerKey())
+			answers[i].ep, answers[i].keySuffix = p, p.providerKey()
+		}(i, p)
+	}
+
+	wg.Wait()
+
+	counts := make(map[string]int)
+
+	for i := range answers {
+		a := &answers[i]
+
+		if a.err == nil {
+			a.agreement = a.agreementKey()
+			counts[a.agreement]++
+		}
+	}
+
+	// more than one value can reach a quorum smaller than a majority, which is a disagreement too
+	var agreed []string
+
+	for actual, n := range counts {
+		if n >= quorum {
+			agreed = append(agreed, actual)
+		}
+	}
+
+	var (
+		chosen   *providerAnswer
+		answered []string
+	)
+
+	res.Providers = make([]ProviderResult, len(answers))
+
+	for i := range answers {
+		a := &answers[i]
+		r := ProviderResult{
+			URI:         a.uri,
+			ActualValue: a.actual,
+			Agreed:      len(agreed) == 1 && a.err == nil && a.agreement == agreed[0],
+		}
+
+		if a.err != nil {
+			r.Error = a.err.Error()
+			answered = append(answered, fmt.Sprintf("`%s` fails: %s", a.uri, a.err))
+		} else {
+			answered = append(answered, fmt.Sprintf("`%s` returns `%s`", a.uri, a.agreement))
+		}
+
+		if r.Agreed {
+			if chosen == nil {
+				chosen = a
+			} else {
+				if a.confirmations < chosen.confirmations {
+					chosen.confirmations = a.confirmations
+				}
+
+				if chosen.confirmationsErr == nil {
+					chosen.confirmationsErr = a.confirmationsErr
+				}
+			}
+		}
+
+		res.Providers[i] = r
+	}
+
+	if chosen == nil {
+		return providerAnswer{}, status.NewCodedError(
+			status.VerificationStatusUnverifiable,
+			status.CodeQuorumNotMet,
+			fmt.Errorf("%d of %d providers must agree, but %s", quorum, len(answers),
+				strings.Join(answered, ", ")),
+		)
+	}
+
+	return *chosen, nil
+}
@@ -0,0 +1,406 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:59:58+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:59:58+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newTestLyingMirrorNode creates a mirror node that returns the given memo for any transaction
+func newTestLyingMirrorNode(t *testing.T, memo string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"transactions": []map[string]interface{}{
+				{
+					"consensus_timestamp": "1568262446.120000000",
+					"memo_base64":         base64.StdEncoding.EncodeToString([]byte(memo)),
+					"result":              hederaResultSuccess,
+				},
+			},
+		})
+	}))
+}
+
+// newTestBlockCypher creates a BlockCypher API on mainnet that returns the given raw transaction
+// for the legacy test transaction, which is confirmed when BlockCypher sees its block, rather than
+// at the block time, and the test block header at height 503275
+func newTestBlockCypher(t *testing.T, txHex string) *httptest.Server {
+	header, blockHash := testBlockHeader(t)
+	txID := testTxID(t, false)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/main/txs/" + txID:
+			if r.URL.Query().Get("includeHex") != "true" {
+				t.Errorf("BlockCypher transaction query = %s", r.URL.RawQuery)
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"hash":          txID,
+				"hex":           txHex,
+				"confirmed":     time.Unix(testBlockTime+97, 0).UTC(),
+				"confirmations": 6,
+			})
+		case "/main/blocks/503275":
+			h := mustDecodeHex(t, header)
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"hash":       blockHash,
+				"height":     503275,
+				"ver":        binary.LittleEndian.Uint32(h[0:4]),
+				"prev_block": hex.EncodeToString(reverseBytes(h[4:36])),
+				"mrkl_root":  btcHeaderMerkleRoot(h),
+				"time":       btcHeaderTime(h),
+				"bits":       binary.LittleEndian.Uint32(h[72:76]),
+				"nonce":      binary.LittleEndian.Uint32(h[76:80]),
+			})
+		case "/main":
+			json.NewEncoder(w).Encode(map[string]interface{}{"height": 503280})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "Not found"})
+		}
+	}))
+}
+
+func Test_verifyQuorum(t *testing.T) {
+	var (
+		honest = newTestMirrorNode(t)
+		liar   = newTestLyingMirrorNode(t, strings.Repeat("00", 32))
+		down   = newTestMirrorNode(t)
+	)
+	defer honest.Close()
+	defer liar.Close()
+	down.Close()
+
+	endpoint := func(quorum int64, primary string, alternates ...string) Endpoint {
+		ep := Endpoint{URL: primary, Network: "testnet", Quorum: &quorum}
+
+		for _, a := range alternates {
+			ep.Alternates = append(ep.Alternates, Endpoint{URL: a})
+		}
+
+		return ep
+	}
+
+	tests := []struct {
+		name       string
+		ep         Endpoint
+		wantErr    error
+		wantAgreed []bool
+	}{
+		{
+			"Single provider",
+			endpoint(0, honest.URL),
+			nil,
+			nil,
+		},
+		{
+			"Majority agrees",
+			endpoint(0, honest.URL, honest.URL+"/", liar.URL),
+			nil,
+			[]bool{true, true, false},
+		},
+		{
+			"Majority agrees on a different value",
+			endpoint(0, liar.URL, liar.URL+"/", honest.URL),
+			status.ErrAnchorValueMismatch,
+			[]bool{true, true, false},
+		},
+		{
+			"Not enough providers answer",
+			endpoint(2, honest.URL, liar.URL, down.URL),
+			status.ErrQuorumNotMet,
+			[]bool{false, false, false},
+		},
+		{
+			"Disagreeing providers both reach quorum",
+			endpoint(1, honest.URL, liar.URL),
+			status.ErrQuorumNotMet,
+			[]bool{false, false},
+		},
+		{
+			"Quorum exceeds providers",
+			endpoint(3, honest.URL, honest.URL+"/"),
+			status.ErrQuorumNotMet,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, true)
+			err := v.verifyHederaTxnData(context.Background(), Result{}, "0.0.1234-1568262436-123456789",
+				testOpReturnValue, tt.ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyHederaTxnData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			r := v.results[0]
+
+			if len(r.Providers) != len(tt.wantAgreed) {
+				t.Fatalf("verifyHederaTxnData() recorded %d providers, want %d", len(r.Providers), len(tt.wantAgreed))
+			}
+
+			for i, p := range r.Providers {
+				if p.Agreed != tt.wantAgreed[i] {
+					t.Errorf("verifyHederaTxnData() recorded provider %d agreed = %v, want %v", i, p.Agreed, tt.wantAgreed[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_verifyBitcoinQuorum(t *testing.T) {
+	esplora := newTestEsplora(t)
+	defer esplora.Close()
+
+	bitcoind := newTestBitcoind(t)
+	defer bitcoind.Close()
+
+	blockCypher := newTestBlockCypher(t, testRawTx(false))
+	defer blockCypher.Close()
+
+	ep := Endpoint{
+		URL:      esplora.URL,
+		Network:  "main",
+		Provider: ProviderEsplora,
+		Alternates: []Endpoint{
+			{URL: bitcoind.URL, Token: "user:pass", Provider: ProviderBitcoind},
+			{URL: blockCypher.URL, Provider: ProviderBlockCypher},
+		},
+		Quorum: int64Ptr(3),
+	}
+
+	v := newTestVerifier(t, true)
+	v.cfg.Record = NewEvidenceBundle()
+
+	err := v.verifyBitcoinBlockMerkleRoot(context.Background(), Result{}, "503275", testOpReturnValue, ep)
+	if err != nil {
+		t.Fatalf("verifyBitcoinBlockMerkleRoot() error = %v", err)
+	}
+
+	for _, p := range v.results[0].Providers {
+		if !p.Agreed {
+			t.Errorf("verifyBitcoinBlockMerkleRoot() recorded provider %+v", p)
+		}
+	}
+
+	// the evidence of each provider is kept apart
+	if v.cfg.Record.Len() != 3 {
+		t.Errorf("verifyBitcoinBlockMerkleRoot() recorded %d evidence, want 3", v.cfg.Record.Len())
+	}
+}
+
+// Test_verifyEthQuorum verifies Ethereum transactions offline using the evidence of each provider,
+// which agree on the data, but not always on the receipt, the transaction or the tip
+func Test_verifyEthQuorum(t *testing.T) {
+	recipient := common.HexToAddress("0x2f3ca2a1d6de4a3b2ef9c4e3e8dd5ff35b8e1b7a")
+	header := &types.Header{Number: big.NewInt(100), Time: 1516080000}
+	signed, _, _ := newTestEthTx(t, recipient, types.ReceiptStatusSuccessful, header)
+	// a transaction of another sender, which a provider returns in place of the requested one
+	other, _, trusted := newTestEthTx(t, recipient, types.ReceiptStatusSuccessful, header)
+
+	honest := newTestEthEvidence(t, signed, types.ReceiptStatusSuccessful)
+	failed := newTestEthEvidence(t, signed, types.ReceiptStatusFailed)
+	forged := newTestEthEvidence(t, other, types.ReceiptStatusSuccessful)
+
+	txnID := signed.Hash().Hex()
+	urls := []string{"http://127.0.0.1:1/a", "http://127.0.0.1:1/b", "http://127.0.0.1:1/c"}
+
+	tests := []struct {
+		name       string
+		txs        []*rawEvidence
+		tips       []int64
+		quorum     int64
+		senders    []string
+		wantErr    error
+		wantAgreed []bool
+	}{
+		{
+			"Providers agree",
+			[]*rawEvidence{honest, honest, honest},
+			[]int64{111, 111, 111},
+			2,
+			nil,
+			nil,
+			[]bool{true, true, true},
+		},
+		{
+			"Provider disagrees on a failed receipt",
+			[]*rawEvidence{failed, honest, honest},
+			[]int64{111, 111, 111},
+			2,
+			nil,
+			nil,
+			[]bool{false, true, true},
+		},
+		{
+			"Majority reports a failed receipt",
+			[]*rawEvidence{honest, failed, failed},
+			[]int64{111, 111, 111},
+			2,
+			nil,
+			status.ErrAnchorTxFailed,
+			[]bool{false, true, true},
+		},
+		{
+			"Provider forges a transaction of a trusted sender",
+			[]*rawEvidence{forged, honest, honest},
+			[]int64{111, 111, 111},
+			2,
+			[]string{trusted.Hex()},
+			status.ErrAnchorUntrusted,
+			[]bool{false, true, true},
+		},
+		{
+			"Provider forges the transaction",
+			[]*rawEvidence{forged, honest, honest},
+			[]int64{111, 111, 111},
+			3,
+			nil,
+			status.ErrQuorumNotMet,
+			[]bool{false, false, false},
+		},
+		{
+			"Provider overstates the confirmations",
+			[]*rawEvidence{honest, honest, honest},
+			[]int64{1000, 105, 111},
+			2,
+			nil,
+			status.ErrInsufficientConfirmations,
+			[]bool{true, true, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := Endpoint{
+				URL:              urls[0],
+				Network:          "mainnet",
+				MinConfirmations: int64Ptr(12),
+				Quorum:           &tt.quorum,
+				Senders:          tt.senders,
+			}
+
+			b := NewEvidenceBundle()
+
+			for i, u := range urls {
+				if i > 0 {
+					ep.Alternates = append(ep.Alternates, Endpoint{URL: u})
+				}
+
+				p := Endpoint{URL: u}
+				b.Add(newTestEvidence(t, "eth/mainnet/tx/"+txnID+p.providerKey(), tt.txs[i]))
+				b.Add(newTestEvidence(t, "eth/mainnet/tip"+p.providerKey(), &chainCount{Count: tt.tips[i]}))
+			}
+
+			v := newTestVerifier(t, true)
+			v.cfg.Offline = true
+			v.cfg.Evidence = b
+
+			err := v.verifyEthTxnData(context.Background(), Result{}, txnID, "abcd", ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyEthTxnData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			r := v.results[0]
+
+			if len(r.Providers) != len(tt.wantAgreed) {
+				t.Fatalf("verifyEthTxnData() recorded %d providers, want %d", len(r.Providers), len(tt.wantAgreed))
+			}
+
+			for i, p := range r.Providers {
+				if p.Agreed != tt.wantAgreed[i] {
+					t.Errorf("verifyEthTxnData() recorded provider %d agreed = %v, want %v", i, p.Agreed, tt.wantAgreed[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_verifyBitcoinTxQuorum(t *testing.T) {
+	esplora := newTestEsplora(t)
+	defer esplora.Close()
+
+	tests := []struct {
+		name    string
+		txHex   string
+		wantErr error
+	}{
+		{
+			"Providers agree despite different block times",
+			testRawTx(false),
+			nil,
+		},
+		{
+			"Provider forges the transaction",
+			testRawTx(true),
+			status.ErrQuorumNotMet,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockCypher := newTestBlockCypher(t, tt.txHex)
+			defer blockCypher.Close()
+
+			ep := Endpoint{
+				URL:     blockCypher.URL,
+				Network: "main",
+				Alternates: []Endpoint{
+					{URL: esplora.URL, Provider: ProviderEsplora},
+				},
+				MinConfirmations: int64Ptr(6),
+				Quorum:           int64Ptr(2),
+			}
+
+			v := newTestVerifier(t, true)
+			err := v.verifyBtcTxnData(context.Background(), Result{}, testTxID(t, false), testOpReturnValue, ep)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("verifyBtcTxnData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if r := v.results[0]; len(r.Providers) != 2 || r.Providers[0].Agreed != (tt.wantErr == nil) {
+				t.Errorf("verifyBtcTxnData() recorded providers %+v", r.Providers)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T19:04:12+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T19:04:12+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+)
+
+// Kinds of a `Registration`
+const (
+	KindAnchorType = "anchor_type"
+	KindBranch     = "branch"
+)
+
+// Target represents what an `AnchorVerifier` verifies, which is either a transaction given by a
+// ProvenDB anchor URI, such as `https://anchor.provendb.com/eth/TX_ID`, or a whole branch
+type Target struct {
+	// Labels are the labels of the branch path to the branch that contains the target
+	Labels []string
+	// Branch is the branch that contains the target
+	Branch *model.EvaluatedBranch
+	// Result is the result template of the checks made against the target
+	Result Result
+	// TxID is the transaction ID in the anchor URI. It is empty for a branch
+	TxID string
+	// ExpectedValue is the value that the transaction should contain. It is empty for a branch
+	ExpectedValue string
+}
+
+// AnchorVerifier verifies targets independently, such as by looking up a transaction on its
+// blockchain. It is registered by an anchor type or a branch label in a `Registry`
+type AnchorVerifier interface {
+	// Name describes the verifier
+	Name() string
+	// Verify verifies the given target and records the checks made using the given checker. The
+	// returned error is a `VerificationStatusError` when the reason is known
+	Verify(ctx context.Context, c *Checker, t Target) error
+}
+
+// Checker records the checks made by an `AnchorVerifier` during a verification
+type Checker struct {
+	v *verifier
+}
+
+// Config returns the config of the verification
+func (c *Checker) Config() *Config {
+	return c.v.cfg
+}
+
+// Check runs the check described by the given result template, whose `Check` and `ExpectedValue`
+// should be filled in. The check function returns the actual value and the reason when the check
+// fails. The result is recorded, and the returned error has its code and location filled in
+func (c *Checker) Check(res Result, check func() (actualValue interface{}, err error)) error {
+	start := time.Now()
+	c.v.start(res)
+
+	actualValue, err := check()
+	return c.v.record(res, start, actualValue, err)
+}
+
+// Lookup gets the evidence of the given key and decodes its value into the given pointer. Like the
+// builtin verifiers, it looks in the supplied evidence bundle and the disk cache before fetching
+// from the given URL using the given function, which fails in offline mode. The evidence is
+// recorded, so it can be saved and verified offline later
+func (c *Checker) Lookup(ctx context.Context, key, uri string, value interface{},
+	fetch func() (interface{}, error)) error {
+	return c.v.lookup(ctx, key, uri, value, fetch)
+}
+
+// VerifyURIs verifies the given anchor URIs using the anchor type verifiers
+func (c *Checker) VerifyURIs(ctx context.Context, res Result, uris []string, expectedValue string) error {
+	return c.v.verifyAnchorURIs(ctx, res, uris, expectedValue)
+}
+
+// Registration represents an `AnchorVerifier` registered in a `Registry`
+type Registration struct {
+	// Kind is either `anchor_type` or `branch`
+	Kind string `json:"kind"`
+	// Key is the anchor type or branch label
+	Key      string         `json:"key"`
+	Verifier AnchorVerifier `json:"-"`
+	// Name is the name of the verifier
+	Name string `json:"name"`
+}
+
+// Registry maps anchor types and branch labels to `AnchorVerifier`s. It is safe for concurrent use
+type Registry struct {
+	mu          sync.RWMutex
+	anchorTypes map[string]AnchorVerifier
+	branches    map[string]AnchorVerifier
+}
+
+// DefaultRegistry is the registry used by a `Config` without its own one, which has the builtin
+// verifiers registered
+var DefaultRegistry = newDefaultRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		anchorTypes: make(map[string]AnchorVerifier),
+		branches:    make(map[string]AnchorVerifier),
+	}
+}
+
+// Clone creates a copy of the registry, which can be changed without affecting the original
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := NewRegistry()
+
+	for k, av := range r.anchorTypes {
+		c.anchorTypes[k] = av
+	}
+
+	for k, av := range r.branches {
+		c.branches[k] = av
+	}
+
+	return c
+}
+
+// RegisterAnchorType registers the verifier of the given anchor type, which replaces the existing
+// one
+func (r *Registry) RegisterAnchorType(anchorType string, av AnchorVerifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.anchorTypes[anchorType] = av
+}
+
+// RegisterBranch registers the verifier of the branches with the given label, which replaces the
+// existing one. The anchor URIs of those branches are only verified by the verifier
+func (r *Registry) RegisterBranch(label string, av AnchorVerifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.branches[label] = av
+}
+
+// AnchorType gets the verifier of the given anchor type, which is nil when not registered
+func (r *Registry) AnchorType(anchorType string) AnchorVerifier {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.anchorTypes[anchorType]
+}
+
+// Branch gets the verifier of the branches with the given label, which is nil when not registered
+func (r *Registry) Branch(label string) AnchorVerifier {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.branches[label]
+}
+
+// Registrations returns all the registered verifiers sorted by their kinds and keys
+func (r *Registry) Registrations() []Registration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var regs []Registration
+
+	for k, av := range r.anchorTypes {
+		regs = append(regs, Registration{Kind: KindAnchorType, Key: k, Verifier: av, Name: av.Name()})
+	}
+
+	for k, av := range r.branches {
+		regs = append(regs, Registration{Kind: KindBranch, Key: k, Verifier: av, Name: av.Name()})
+	}
+
+	sort.Slice(regs, func(i, j int) bool {
+		if regs[i].Kind != regs[j].Kind {
+			return regs[i].Kind < regs[j].Kind
+		}
+
+		return regs[i].Key < regs[j].Key
+	})
+
+	return regs
+}
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.RegisterAnchorType("eth", &ethTxVerifier{"eth"})
+	r.RegisterAnchorType("eth_mainnet", &ethTxVerifier{"eth_mainnet"})
+	r.RegisterAnchorType("eth_elastos", &ethTxVerifier{"eth_elastos"})
+	r.RegisterAnchorType("btc", &btcTxVerifier{"btc"})
+	r.RegisterAnchorType("btc_mainnet", &btcTxVerifier{"btc_mainnet"})
+	r.RegisterAnchorType("hedera", &hederaTxVerifier{"hedera"})
+	r.RegisterAnchorType("hedera_mainnet", &hederaTxVerifier{"hedera_mainnet"})
+	r.RegisterBranch(btcAnchorBranch, &btcBranchVerifier{})
+
+	return r
+}
+
+// ethTxVerifier verifies the data of an Ethereum transaction using the endpoint of its anchor type
+type ethTxVerifier struct {
+	anchorType string
+}
+
+func (e *ethTxVerifier) Name() string {
+	return "Ethereum transaction data"
+}
+
+func (e *ethTxVerifier) Verify(ctx context.Context, c *Checker, t Target) error {
+	return c.v.verifyEthTxnData(ctx, t.Result, t.TxID, t.ExpectedValue, c.Config().Endpoint(e.anchorType))
+}
+
+// btcTxVerifier verifies the OP_RETURN of a Bitcoin transaction using the endpoint of its anchor
+// type
+type btcTxVerifier struct {
+	anchorType string
+}
+
+func (b *btcTxVerifier) Name() string {
+	return "Bitcoin transaction OP_RETURN"
+}
+
+func (b *btcTxVerifier) Verify(ctx context.Context, c *Checker, t Target) error {
+	return c.v.verifyBtcTxnData(ctx, t.Result, t.TxID, t.ExpectedValue, c.Config().Endpoint(b.anchorType))
+}
+
+// hederaTxVerifier verifies the memo of a Hedera transaction using the endpoint of its anchor type
+type hederaTxVerifier struct {
+	anchorType string
+}
+
+func (h *hederaTxVerifier) Name() string {
+	return "Hedera transaction memo"
+}
+
+func (h *hederaTxVerifier) Verify(ctx context.Context, c *Checker, t Target) error {
+	return c.v.verifyHederaTxnData(ctx, t.Result, t.TxID, t.ExpectedValue, c.Config().Endpoint(h.anchorType))
+}
+
+// btcBranchVerifier verifies a `btc_anchor_branch`, including its anchor URIs, the Bitcoin block
+// merkle roots of its anchors and its Bitcoin transaction OP_RETURN
+type btcBranchVerifier struct{}
+
+func (b *btcBranchVerifier) Name() string {
+	return "Bitcoin transaction OP_RETURN and block merkle root"
+}
+
+func (b *btcBranchVerifier) Verify(ctx context.Context, c *Checker, t Target) error {
+	return c.v.verifyBitcoinBranch(ctx, t.Labels, t.Branch)
+}
@@ -0,0 +1,199 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T19:31:55+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T19:31:55+11:00
+ */
+
+package anchor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+// fakeVerifier is a test double, which looks up the anchored values from a map
+type fakeVerifier struct {
+	values map[string]string
+}
+
+func (f *fakeVerifier) Name() string {
+	return "fake transaction data"
+}
+
+func (f *fakeVerifier) Verify(ctx context.Context, c *Checker, t Target) error {
+	res := t.Result
+	res.Check = "fake_tx_data"
+	res.ExpectedValue = t.ExpectedValue
+
+	if t.Branch != nil {
+		// verify the branch as a whole
+		res.ExpectedValue = t.Branch.OpReturnValue
+		t.TxID = t.Branch.BtcTxID
+	}
+
+	return c.Check(res, func() (interface{}, error) {
+		var value string
+
+		err := c.Lookup(ctx, "fake#"+t.TxID, "fake://"+t.TxID, &value, func() (interface{}, error) {
+			v, ok := f.values[t.TxID]
+			if !ok {
+				return nil, fmt.Errorf("transaction `%s` is not found", t.TxID)
+			}
+
+			return v, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if value != res.ExpectedValue {
+			return value, status.NewVerificationStatusError(status.VerificationStatusFalsified,
+				fmt.Errorf("transaction `%s` has data `%s`, but expect `%s`", t.TxID, value, res.ExpectedValue))
+		}
+
+		return value, nil
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	const expectedValue = "592b3fbc543c066dcfdbb51a02f843ee312289694b0977e36b7c57e983c75ba8"
+
+	fake := &fakeVerifier{values: map[string]string{
+		"aa": expectedValue,
+		"bb": "00",
+	}}
+
+	registry := DefaultRegistry.Clone()
+	registry.RegisterAnchorType("fake", fake)
+	registry.RegisterBranch(btcAnchorBranch, fake)
+
+	newProof := func(label, uri string) *model.EvaluatedProof {
+		return &model.EvaluatedProof{
+			Branches: []model.EvaluatedBranch{
+				{
+					Label:         label,
+					OpReturnValue: expectedValue,
+					BtcTxID:       "aa",
+					Anchors: []model.EvaluatedAnchor{
+						{
+							Type:          "fake",
+							AnchorID:      "1",
+							URIs:          []string{uri},
+							ExpectedValue: expectedValue,
+							Op:            2,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		proof       *model.EvaluatedProof
+		wantErr     error
+		wantResults int
+	}{
+		{
+			"Verify anchor type",
+			newProof("fake_anchor_branch", "https://anchor.provendb.com/fake/aa"),
+			nil,
+			1,
+		},
+		{
+			"Falsify anchor type",
+			newProof("fake_anchor_branch", "https://anchor.provendb.com/fake/bb"),
+			status.ErrAnchorValueMismatch,
+			1,
+		},
+		{
+			"Lookup failure",
+			newProof("fake_anchor_branch", "https://anchor.provendb.com/fake/cc"),
+			status.ErrAnchorUnreachable,
+			1,
+		},
+		{
+			"Unregistered anchor type",
+			newProof("fake_anchor_branch", "https://anchor.provendb.com/unknown/aa"),
+			status.ErrAnchorUnsupported,
+			1,
+		},
+		{
+			"Replace branch verifier",
+			newProof(btcAnchorBranch, "https://anchor.provendb.com/unknown/aa"),
+			nil,
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t, true)
+			cfg.Registry = registry
+
+			results, err := Verify(context.Background(), cfg, tt.proof)
+
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(results) != tt.wantResults {
+				t.Fatalf("Verify() recorded %d results, want %d", len(results), tt.wantResults)
+			}
+		})
+	}
+
+	if DefaultRegistry.AnchorType("fake") != nil || DefaultRegistry.Branch(btcAnchorBranch) == fake {
+		t.Errorf("Clone() shares registrations with the original")
+	}
+}
+
+func TestRegistryRegistrations(t *testing.T) {
+	regs := DefaultRegistry.Registrations()
+
+	want := []struct {
+		kind string
+		key  string
+	}{
+		{KindAnchorType, "btc"},
+		{KindAnchorType, "btc_mainnet"},
+		{KindAnchorType, "eth"},
+		{KindAnchorType, "eth_elastos"},
+		{KindAnchorType, "eth_mainnet"},
+		{KindAnchorType, "hedera"},
+		{KindAnchorType, "hedera_mainnet"},
+		{KindBranch, btcAnchorBranch},
+	}
+
+	if len(regs) != len(want) {
+		t.Fatalf("Registrations() = %v, want %v", regs, want)
+	}
+
+	for i, w := range want {
+		if regs[i].Kind != w.kind || regs[i].Key != w.key || regs[i].Name == "" {
+			t.Errorf("Registrations()[%d] = %+v, want %v", i, regs[i], w)
+		}
+	}
+}
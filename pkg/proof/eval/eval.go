@@ -32,6 +32,7 @@ import (
 	"hash"
 	"strings"
 
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/queue"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/sha3"
@@ -42,111 +43,96 @@ const (
 	SignaturePrefix = "sig:"
 )
 
-// Eval evaluates given Proof JSON and calculate anchor infos such as merkle root
-func Eval(proof interface{}) (result map[string]interface{}, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			result = nil
-			err = fmt.Errorf("failed to evaluate Proof: %s", r)
-		}
-	}()
-
-	value := proof.(map[string]interface{})
-	result = make(map[string]interface{})
-
-	hash := value["hash"]
-
-	result["hash"] = hash
-	result["hash_id_node"] = value["hash_id_node"]
-	result["hash_submitted_node_at"] = value["hash_submitted_node_at"]
-	result["hash_id_core"] = value["hash_id_core"]
-	result["hash_submitted_core_at"] = value["hash_submitted_core_at"]
-
-	hashBA, err := hex.DecodeString(hash.(string))
+// Eval evaluates given Proof and calculate anchor infos such as merkle root
+func Eval(proof *model.Proof) (*model.EvaluatedProof, error) {
+	err := proof.Validate()
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to evaluate Proof: %w", err)
 	}
 
-	result["branches"] = evalBranches(hashBA, value["branches"].([]interface{}))
+	hashBA, err := hex.DecodeString(proof.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate Proof: %w", err)
+	}
 
-	return result, nil
+	return &model.EvaluatedProof{
+		Hash:                proof.Hash,
+		HashIDNode:          proof.HashIDNode,
+		HashSubmittedNodeAt: proof.HashSubmittedNodeAt,
+		HashIDCore:          proof.HashIDCore,
+		HashSubmittedCoreAt: proof.HashSubmittedCoreAt,
+		Branches:            evalBranches(hashBA, proof.Branches),
+	}, nil
 }
 
 // Branch evaluates a Chainpoint branch and returns the result branch and end hash
-func Branch(startHash []byte, branch map[string]interface{}) (resultBranch map[string]interface{}, endHash []byte) {
+func Branch(startHash []byte, branch *model.Branch) (resultBranch model.EvaluatedBranch, endHash []byte) {
 	currHash := startHash
-	resultBranch = make(map[string]interface{})
 
 	var (
-		resultAnchors []interface{}
-		isBTC         bool
-		btcQueue      *queue.Queue
+		isBTC    bool
+		btcQueue *queue.Queue
 	)
 
-	if l := branch["label"]; l != nil {
-		resultBranch["label"] = l
+	resultBranch.Label = branch.Label
+	resultBranch.OpCount = len(branch.Ops)
+	resultBranch.StartHash = hex.EncodeToString(startHash)
 
-		if l == "btc_anchor_branch" {
-			isBTC = true
-			btcQueue = queue.New()
-		}
+	if branch.Label == "btc_anchor_branch" {
+		isBTC = true
+		btcQueue = queue.New()
 	}
 
-	checkSig := func(operand string) {
+	checkSig := func(operand string, idx int) {
 		if strings.HasPrefix(operand, SignaturePrefix) {
-			resultBranch["sig"] = operand[len(SignaturePrefix):]
-			resultBranch["sigHash"] = hex.EncodeToString(currHash)
+			resultBranch.Sig = operand[len(SignaturePrefix):]
+			resultBranch.SigHash = hex.EncodeToString(currHash)
+			resultBranch.SigOp = idx
 		}
 	}
 
-	currBranchOps := branch["ops"].([]interface{})
-
-	for j := 0; j < len(currBranchOps); j++ {
-		currBranchOp := currBranchOps[j].(map[string]interface{})
-
-		if r := currBranchOp["r"]; r != nil {
-			op := r.(string)
-			checkSig(op)
-			currHash = append(currHash, str2ByteArray(op)...)
-		} else if l := currBranchOp["l"]; l != nil {
-			op := l.(string)
-			checkSig(op)
-			currHash = append(str2ByteArray(op), currHash...)
-		} else if op := currBranchOp["op"]; op != nil {
-			switch algo := op.(string); algo {
-			case "sha-224":
+	for i, op := range branch.Ops {
+		if op.R != "" {
+			checkSig(op.R, i)
+			currHash = append(currHash, str2ByteArray(op.R)...)
+		} else if op.L != "" {
+			checkSig(op.L, i)
+			currHash = append(str2ByteArray(op.L), currHash...)
+		} else if op.Op != "" {
+			switch algo := op.Op; algo {
+			case model.OpSha224:
 				currHash = hashData(currHash, sha256.New224())
-			case "sha-256":
+			case model.OpSha256:
 				currHash = hashData(currHash, sha256.New())
-			case "sha-384":
+			case model.OpSha384:
 				currHash = hashData(currHash, sha512.New384())
-			case "sha-512":
+			case model.OpSha512:
 				currHash = hashData(currHash, sha512.New())
-			case "sha3-224":
+			case model.OpSha3224:
 				currHash = hashData(currHash, sha3.New224())
-			case "sha3-256":
+			case model.OpSha3256:
 				currHash = hashData(currHash, sha3.New256())
-			case "sha3-384":
+			case model.OpSha3384:
 				currHash = hashData(currHash, sha3.New384())
-			case "sha3-512":
+			case model.OpSha3512:
 				currHash = hashData(currHash, sha3.New512())
-			case "sha-256-x2":
+			case model.OpSha256x2:
 				hasher := sha256.New()
 				currHash = hashData(currHash, hasher)
 				hasher.Reset()
 				currHash = hashData(currHash, hasher)
 
 				if isBTC && btcQueue != nil {
-					resultBranch["opReturnValue"] = hex.EncodeToString(btcQueue.Peek().([]byte))
-					resultBranch["btcTxId"] = getReverseHexStr(currHash)
+					resultBranch.OpReturnValue = hex.EncodeToString(btcQueue.Peek().([]byte))
+					resultBranch.BtcTxID = getReverseHexStr(currHash)
 
 					btcQueue = nil
 				}
 			default:
 				log.Warnf("The hashing algorithm %s is not supported", algo)
 			}
-		} else if anchors := currBranchOp["anchors"]; anchors != nil {
-			resultAnchors = append(resultAnchors, evalAnchors(currHash, anchors.([]interface{}))...)
+		} else if op.Anchors != nil {
+			resultBranch.Anchors = append(resultBranch.Anchors, evalAnchors(currHash, i, op.Anchors)...)
 		}
 
 		if isBTC && btcQueue != nil {
@@ -161,22 +147,20 @@ func Branch(startHash []byte, branch map[string]interface{}) (resultBranch map[s
 		}
 	}
 
-	resultBranch["anchors"] = resultAnchors
+	resultBranch.EndHash = hex.EncodeToString(currHash)
 
-	if branches := branch["branches"]; branches != nil {
-		resultBranch["branches"] = evalBranches(currHash, branches.([]interface{}))
+	if branch.Branches != nil {
+		resultBranch.Branches = evalBranches(currHash, branch.Branches)
 	}
 
 	return resultBranch, currHash
 }
 
-func evalBranches(startHash []byte, branches []interface{}) (result []interface{}) {
+func evalBranches(startHash []byte, branches []model.Branch) (result []model.EvaluatedBranch) {
 	currHash := startHash
 
-	for i := 0; i < len(branches); i++ {
-		branch := branches[i].(map[string]interface{})
-
-		resultBranch, endHash := Branch(currHash, branch)
+	for i := range branches {
+		resultBranch, endHash := Branch(currHash, &branches[i])
 
 		result = append(result, resultBranch)
 		currHash = endHash
@@ -185,25 +169,21 @@ func evalBranches(startHash []byte, branches []interface{}) (result []interface{
 	return result
 }
 
-func evalAnchors(currHash []byte, anchors []interface{}) (result []interface{}) {
-	for i := 0; i < len(anchors); i++ {
-		anchor := anchors[i].(map[string]interface{})
-
-		resultAnchor := map[string]interface{}{
-			"type":      anchor["type"],
-			"anchor_id": anchor["anchor_id"],
-		}
-
-		if uris := anchor["uris"]; uris != nil {
-			resultAnchor["uris"] = uris
+func evalAnchors(currHash []byte, opIdx int, anchors []model.Anchor) (result []model.EvaluatedAnchor) {
+	for _, anchor := range anchors {
+		resultAnchor := model.EvaluatedAnchor{
+			Type:     anchor.Type,
+			AnchorID: anchor.AnchorID,
+			URIs:     anchor.URIs,
+			Op:       opIdx,
 		}
 
-		if aType := anchor["type"]; aType == "btc" {
+		if anchor.Type == "btc" || anchor.Type == "tbtc" {
 			// BTC merkle root values are in little endian byte order, which are different in
 			// Chainpoint's big endian byte order
-			resultAnchor["expected_value"] = getReverseHexStr(currHash)
+			resultAnchor.ExpectedValue = getReverseHexStr(currHash)
 		} else {
-			resultAnchor["expected_value"] = hex.EncodeToString(currHash)
+			resultAnchor.ExpectedValue = hex.EncodeToString(currHash)
 		}
 
 		result = append(result, resultAnchor)
@@ -28,67 +28,85 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/testutil"
 	log "github.com/sirupsen/logrus"
 )
 
 func TestEval(t *testing.T) {
 	type args struct {
-		proof interface{}
+		proof *model.Proof
 	}
 	tests := []struct {
 		name       string
 		args       args
-		wantResult interface{}
+		wantResult *model.EvaluatedProof
 		wantErr    bool
 	}{
 		{
 			"Evaluate Chainpoint v3 Proof - proof1.json",
 			args{
-				testutil.LoadJSON(t, "proof1.json"),
+				testutil.LoadProof(t, "proof1.json"),
 			},
-			testutil.LoadJSON(t, "evaluated_proof1.json"),
+			testutil.LoadEvaluatedProof(t, "evaluated_proof1.json"),
 			false,
 		},
 		{
 			"Evaluate Chainpoint v3 Proof - proof2.json",
 			args{
-				testutil.LoadJSON(t, "proof2.json"),
+				testutil.LoadProof(t, "proof2.json"),
 			},
-			testutil.LoadJSON(t, "evaluated_proof2.json"),
+			testutil.LoadEvaluatedProof(t, "evaluated_proof2.json"),
 			false,
 		},
 		{
 			"Evaluate Chainpoint v3 Proof - proof3.json",
 			args{
-				testutil.LoadJSON(t, "proof3.json"),
+				testutil.LoadProof(t, "proof3.json"),
 			},
-			testutil.LoadJSON(t, "evaluated_proof3.json"),
+			testutil.LoadEvaluatedProof(t, "evaluated_proof3.json"),
 			false,
 		},
 		{
 			"Evaluate Chainpoint v3 Proof - proof4.json",
 			args{
-				testutil.LoadJSON(t, "proof4.json"),
+				testutil.LoadProof(t, "proof4.json"),
 			},
-			testutil.LoadJSON(t, "evaluated_proof4.json"),
+			testutil.LoadEvaluatedProof(t, "evaluated_proof4.json"),
 			false,
 		},
 		{
 			"Evaluate Chainpoint v3 Proof - proof5.json",
 			args{
-				testutil.LoadJSON(t, "proof5.json"),
+				testutil.LoadProof(t, "proof5.json"),
 			},
-			testutil.LoadJSON(t, "evaluated_proof5.json"),
+			testutil.LoadEvaluatedProof(t, "evaluated_proof5.json"),
 			false,
 		},
 		{
-			"Evaluate corrupted JSON",
+			"Evaluate Proof with corrupted hash",
 			args{
-				"I am not JSON",
+				&model.Proof{
+					Hash:     "I am not hex",
+					Branches: []model.Branch{},
+				},
 			},
-			// nil map
-			*new(map[string]interface{}),
+			nil,
+			true,
+		},
+		{
+			"Evaluate Proof with malformed op",
+			args{
+				&model.Proof{
+					Hash: "ffff",
+					Branches: []model.Branch{
+						{
+							Ops: []model.Op{{}},
+						},
+					},
+				},
+			},
+			nil,
 			true,
 		},
 	}
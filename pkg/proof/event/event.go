@@ -0,0 +1,57 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T14:05:12+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T14:05:12+11:00
+ */
+
+// Package event defines the observer interface that receives the typed events emitted during a
+// verification. The events themselves are defined by the packages that emit them, such as
+// `anchor.AnchorStarted` and `verify.CollectionHashed`
+package event
+
+// Event is a typed verification event
+type Event interface {
+	// EventName returns the stable name of the event, such as `anchor.started`, which can be used
+	// when forwarding the event to logs
+	EventName() string
+}
+
+// Sink receives verification events. As checks run concurrently, `Handle` can be called from
+// multiple goroutines at the same time and must be safe for concurrent use. Verification is
+// blocked until `Handle` returns
+type Sink interface {
+	Handle(e Event)
+}
+
+// SinkFunc is an adapter to allow the use of an ordinary function as a `Sink`
+type SinkFunc func(e Event)
+
+// Handle calls f(e)
+func (f SinkFunc) Handle(e Event) {
+	f(e)
+}
+
+// Emit sends the event to the sink. It is a no-op when the sink is nil
+func Emit(s Sink, e Event) {
+	if s != nil {
+		s.Handle(e)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T15:20:44+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T15:20:44+11:00
+ */
+
+package model
+
+// EvaluatedProof represents an evaluated Chainpoint Proof, where the expected value of each anchor
+// is calculated
+type EvaluatedProof struct {
+	Hash                string            `json:"hash"`
+	HashIDNode          string            `json:"hash_id_node"`
+	HashSubmittedNodeAt string            `json:"hash_submitted_node_at"`
+	HashIDCore          string            `json:"hash_id_core"`
+	HashSubmittedCoreAt string            `json:"hash_submitted_core_at"`
+	Branches            []EvaluatedBranch `json:"branches"`
+}
+
+// EvaluatedBranch represents an evaluated Chainpoint Proof branch
+type EvaluatedBranch struct {
+	Label string `json:"label,omitempty"`
+	// OpCount is the number of ops in the branch
+	OpCount int `json:"opCount,omitempty"`
+	// StartHash is the hash before applying the first op of the branch
+	StartHash string `json:"startHash,omitempty"`
+	// EndHash is the hash after applying the last op of the branch
+	EndHash string            `json:"endHash,omitempty"`
+	Anchors []EvaluatedAnchor `json:"anchors"`
+	// Sig is the signature embedded in the branch using a `sig:` prefixed operand
+	Sig string `json:"sig,omitempty"`
+	// SigHash is the hash signed by `Sig`
+	SigHash string `json:"sigHash,omitempty"`
+	// SigOp is the index of the op that contains `Sig`
+	SigOp int `json:"sigOp,omitempty"`
+	// OpReturnValue is the Bitcoin transaction OP_RETURN value of a `btc_anchor_branch`
+	OpReturnValue string `json:"opReturnValue,omitempty"`
+	// BtcTxID is the Bitcoin transaction ID of a `btc_anchor_branch`
+	BtcTxID  string            `json:"btcTxId,omitempty"`
+	Branches []EvaluatedBranch `json:"branches,omitempty"`
+}
+
+// EvaluatedAnchor represents an evaluated Chainpoint Proof anchor
+type EvaluatedAnchor struct {
+	Type     string   `json:"type"`
+	AnchorID string   `json:"anchor_id"`
+	URIs     []string `json:"uris,omitempty"`
+	// ExpectedValue is the value that the anchor URIs or the blockchain should return
+	ExpectedValue string `json:"expected_value"`
+	// Op is the index of the op that contains the anchor in its branch
+	Op int `json:"op"`
+}
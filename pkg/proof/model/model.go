@@ -0,0 +1,245 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T15:20:44+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T15:20:44+11:00
+ */
+
+// Package model contains the typed Chainpoint v3 Proof model and its evaluated counterpart
+package model
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Hashing algorithms of an `Op`
+const (
+	OpSha224   = "sha-224"
+	OpSha256   = "sha-256"
+	OpSha384   = "sha-384"
+	OpSha512   = "sha-512"
+	OpSha3224  = "sha3-224"
+	OpSha3256  = "sha3-256"
+	OpSha3384  = "sha3-384"
+	OpSha3512  = "sha3-512"
+	OpSha256x2 = "sha-256-x2"
+)
+
+var hashOps = map[string]bool{
+	OpSha224:   true,
+	OpSha256:   true,
+	OpSha384:   true,
+	OpSha512:   true,
+	OpSha3224:  true,
+	OpSha3256:  true,
+	OpSha3384:  true,
+	OpSha3512:  true,
+	OpSha256x2: true,
+}
+
+// Proof represents a Chainpoint v3 Proof
+type Proof struct {
+	Context             string   `json:"@context"`
+	Type                string   `json:"type"`
+	Hash                string   `json:"hash"`
+	HashIDNode          string   `json:"hash_id_node"`
+	HashSubmittedNodeAt string   `json:"hash_submitted_node_at"`
+	HashIDCore          string   `json:"hash_id_core"`
+	HashSubmittedCoreAt string   `json:"hash_submitted_core_at"`
+	Branches            []Branch `json:"branches"`
+}
+
+// Branch represents a Chainpoint Proof branch
+type Branch struct {
+	Label    string   `json:"label,omitempty"`
+	Ops      []Op     `json:"ops"`
+	Branches []Branch `json:"branches,omitempty"`
+}
+
+// Op represents a Chainpoint Proof operation. Exactly one of its fields is set
+type Op struct {
+	// L is the operand to be prepended to the current hash
+	L string `json:"l,omitempty"`
+	// R is the operand to be appended to the current hash
+	R string `json:"r,omitempty"`
+	// Op is the hashing algorithm to be applied to the current hash, such as `sha-256`
+	Op string `json:"op,omitempty"`
+	// Anchors are the anchors of the current hash
+	Anchors []Anchor `json:"anchors,omitempty"`
+}
+
+// Anchor represents a Chainpoint Proof anchor
+type Anchor struct {
+	Type     string   `json:"type"`
+	AnchorID string   `json:"anchor_id"`
+	URIs     []string `json:"uris,omitempty"`
+}
+
+// FieldError is returned when a field of a Chainpoint Proof is malformed
+type FieldError struct {
+	// Field is the path to the malformed field, such as `branches[0].ops[3].op`
+	Field string
+	// Branches are the labels of the branches from the top level branch to the branch that
+	// contains the malformed field. It is empty when the field is not in a branch
+	Branches []string
+	// Op is the index of the op that contains the malformed field, or -1 when the field is not in
+	// an op
+	Op  int
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid Chainpoint Proof field `%s`: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldErrorf(field string, branches []string, op int, format string, a ...interface{}) error {
+	return &FieldError{
+		Field:    field,
+		Branches: branches,
+		Op:       op,
+		Err:      fmt.Errorf(format, a...),
+	}
+}
+
+// FromJSON decodes a Chainpoint Proof from JSON. Unknown fields are rejected. `Validate` should be
+// called before evaluating the decoded Proof
+func FromJSON(data []byte) (*Proof, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	p := &Proof{}
+
+	err := dec.Decode(p)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode Chainpoint Proof: %s", err)
+	}
+
+	return p, nil
+}
+
+// FromValue decodes a Chainpoint Proof from a Proof JSON interface{}, such as the one decoded from
+// msgpack. A `*Proof` is returned as is. `Validate` should be called before evaluating the decoded
+// Proof
+func FromValue(v interface{}) (*Proof, error) {
+	switch p := v.(type) {
+	case *Proof:
+		if p == nil {
+			return nil, errors.New("Chainpoint Proof is nil")
+		}
+
+		return p, nil
+	case []byte:
+		return FromJSON(p)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode Chainpoint Proof: %s", err)
+	}
+
+	return FromJSON(data)
+}
+
+// Validate checks the fields that are required to evaluate the Proof
+func (p *Proof) Validate() error {
+	if p.Hash == "" {
+		return fieldErrorf("hash", nil, -1, "is missing")
+	}
+
+	if _, err := hex.DecodeString(p.Hash); err != nil {
+		return fieldErrorf("hash", nil, -1, "%s", err)
+	}
+
+	if p.Branches == nil {
+		return fieldErrorf("branches", nil, -1, "is missing")
+	}
+
+	return validateBranches("branches", nil, p.Branches)
+}
+
+func validateBranches(field string, parents []string, branches []Branch) error {
+	for i := range branches {
+		b := &branches[i]
+		labels := append(append([]string{}, parents...), b.Label)
+
+		err := b.validate(fmt.Sprintf("%s[%d]", field, i), labels)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Branch) validate(field string, labels []string) error {
+	if b.Ops == nil {
+		return fieldErrorf(field+".ops", labels, -1, "is missing")
+	}
+
+	for i := range b.Ops {
+		err := b.Ops[i].validate(fmt.Sprintf("%s.ops[%d]", field, i), labels, i)
+		if err != nil {
+			return err
+		}
+	}
+
+	return validateBranches(field+".branches", labels, b.Branches)
+}
+
+func (o *Op) validate(field string, labels []string, idx int) error {
+	n := 0
+
+	for _, set := range []bool{o.L != "", o.R != "", o.Op != "", o.Anchors != nil} {
+		if set {
+			n++
+		}
+	}
+
+	if n != 1 {
+		return fieldErrorf(field, labels, idx, "must have exactly one of `l`, `r`, `op` and `anchors`")
+	}
+
+	if o.Op != "" && !hashOps[o.Op] {
+		return fieldErrorf(field+".op", labels, idx, "unsupported hashing algorithm `%s`", o.Op)
+	}
+
+	for i, a := range o.Anchors {
+		f := fmt.Sprintf("%s.anchors[%d]", field, i)
+
+		if a.Type == "" {
+			return fieldErrorf(f+".type", labels, idx, "is missing")
+		}
+
+		if a.AnchorID == "" {
+			return fieldErrorf(f+".anchor_id", labels, idx, "is missing")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T15:20:44+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T15:20:44+11:00
+ */
+
+package model
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+)
+
+func loadTestData(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("../testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestFromJSONAndValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		wantField string
+		wantErr   bool
+	}{
+		{
+			"Decode proof1.json",
+			loadTestData(t, "proof1.json"),
+			"",
+			false,
+		},
+		{
+			"Decode proof5.json",
+			loadTestData(t, "proof5.json"),
+			"",
+			false,
+		},
+		{
+			"Decode corrupted JSON",
+			[]byte("I am not JSON"),
+			"",
+			true,
+		},
+		{
+			"Decode Proof with unknown field",
+			[]byte(`{"hash": "ffff", "branches": [], "unknown": 1}`),
+			"",
+			true,
+		},
+		{
+			"Decode Proof with corrupted hash",
+			[]byte(`{"hash": "I am not hex", "branches": []}`),
+			"hash",
+			true,
+		},
+		{
+			"Decode Proof without branches",
+			[]byte(`{"hash": "ffff"}`),
+			"branches",
+			true,
+		},
+		{
+			"Decode Proof with empty op",
+			[]byte(`{"hash": "ffff", "branches": [{"ops": [{"op": "sha-256"}, {}]}]}`),
+			"branches[0].ops[1]",
+			true,
+		},
+		{
+			"Decode Proof with unsupported hashing algorithm",
+			[]byte(`{"hash": "ffff", "branches": [{"ops": []}, {"ops": [{"op": "md5"}]}]}`),
+			"branches[1].ops[0].op",
+			true,
+		},
+		{
+			"Decode Proof with anchor missing anchor_id",
+			[]byte(`{"hash": "ffff", "branches": [{"ops": [], "branches": [{"ops": [{"anchors": [{"type": "cal"}]}]}]}]}`),
+			"branches[0].branches[0].ops[0].anchors[0].anchor_id",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := FromJSON(tt.data)
+			if err == nil {
+				err = p.Validate()
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantField != "" {
+				var fe *FieldError
+
+				if !errors.As(err, &fe) || fe.Field != tt.wantField {
+					t.Errorf("FromJSON() error = %v, want field `%s`", err, tt.wantField)
+				}
+			}
+		})
+	}
+}
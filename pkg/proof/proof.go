@@ -32,13 +32,16 @@ import (
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/binary"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/eval"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/schema"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
 )
 
-// Verify verifies a given Chainpoint Proof in either base64 binary string or JSON interface{}
-func Verify(ctx context.Context, rawProof interface{}) (
-	st status.VerificationStatus, evaledPf map[string]interface{}, er error) {
+// Verify verifies a given Chainpoint Proof in either base64 binary string, JSON interface{} or
+// `*model.Proof` using the given anchor verification config. When the reason of a failed
+// verification is known, the returned error is a `*status.VerificationStatusError` with its code
+func Verify(ctx context.Context, cfg *anchor.Config, rawProof interface{}) (
+	st status.VerificationStatus, evaledPf *model.EvaluatedProof, er error) {
 	var proof interface{}
 
 	switch p := rawProof.(type) {
@@ -50,33 +53,39 @@ func Verify(ctx context.Context, rawProof interface{}) (
 			return
 		}
 		proof = pf
-	case map[string]interface{}:
+	case map[string]interface{}, *model.Proof:
 		proof = p
 	default:
 		er = fmt.Errorf("unsupported Chainpoint Proof format %T", p)
 		return
 	}
 
+	var pf *model.Proof
+
 	err := schema.Verify(proof)
+	if err == nil {
+		pf, err = model.FromValue(proof)
+	}
+	if err == nil {
+		err = pf.Validate()
+	}
 	if err != nil {
 		st = status.VerificationStatusFalsified
-		er = err
+		er = schema.NewInvalidError(err)
 		return
 	}
 
-	evaluatedProof, err := eval.Eval(proof)
+	evaluatedProof, err := eval.Eval(pf)
 	if err != nil {
 		st = status.VerificationStatusFalsified
-		er = err
+		er = schema.NewInvalidError(err)
 		return
 	}
 
-	err = anchor.Verify(ctx, evaluatedProof)
+	_, err = anchor.Verify(ctx, cfg, evaluatedProof)
 	if err != nil {
-		if se, ok := err.(*status.VerificationStatusError); ok {
+		if se := status.Of(err); se != nil {
 			st = se.Status
-			er = se.Err
-			return
 		}
 
 		er = err
@@ -29,11 +29,17 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/testutil"
 )
 
 func TestVerify(t *testing.T) {
+	cfg, err := anchor.DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	p2Falsified := testutil.LoadFile(t, "falsified_proof2_base64.txt")
 	defer p2Falsified.Close()
 
@@ -89,6 +95,15 @@ func TestVerify(t *testing.T) {
 			status.VerificationStatusVerified,
 			false,
 		},
+		{
+			"Verify Chainpoint Proof (*model.Proof) - proof3.json",
+			args{
+				context.Background(),
+				testutil.LoadProof(t, "proof3.json"),
+			},
+			status.VerificationStatusVerified,
+			false,
+		},
 		{
 			"Verify Chainpoint Proof (base64) - falsified_proof3_base64.txt",
 			args{
@@ -101,7 +116,7 @@ func TestVerify(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotSt, _, err := Verify(tt.args.ctx, tt.args.rawProof)
+			gotSt, _, err := Verify(tt.args.ctx, cfg, tt.args.rawProof)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
 				return
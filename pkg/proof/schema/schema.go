@@ -29,6 +29,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
 	"github.com/xeipuuv/gojsonschema"
 )
 
@@ -72,12 +74,15 @@ const ChainpointProofSchemaV3 = `{
       "properties": {
         "type": {
           "description": "A trust anchor",
-          "title": "One of the known trust anchor types. Calendar (cal), Ethereum (eth), and Bitcoin (btc).",
+          "title": "One of the known trust anchor types. Calendar (cal), Ethereum (eth), and Bitcoin (btc), and their testnets (tcal, teth and tbtc).",
           "type": "string",
           "enum": [
             "cal",
+            "tcal",
             "eth",
-            "btc"
+            "teth",
+            "btc",
+            "tbtc"
           ]
         },
         "anchor_id": {
@@ -247,3 +252,16 @@ func Verify(proof interface{}) (err error) {
 
 	return errors.New(b.String())
 }
+
+// NewInvalidError wraps an error about a malformed Proof, such as the one returned by `Verify` or
+// `model.Proof.Validate`, as a falsified `VerificationStatusError` with `CodeSchemaInvalid`
+func NewInvalidError(err error) *status.VerificationStatusError {
+	se := status.NewCodedError(status.VerificationStatusFalsified, status.CodeSchemaInvalid, err)
+
+	var fe *model.FieldError
+	if errors.As(err, &fe) && len(fe.Branches) > 0 {
+		se.At(&status.Location{Branches: fe.Branches, Op: fe.Op})
+	}
+
+	return se
+}
@@ -24,6 +24,12 @@
 
 package status
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // VerificationStatus represents the verification status type
 type VerificationStatus int
 
@@ -36,10 +42,120 @@ const (
 	VerificationStatusVerified
 )
 
-// VerificationStatusError combines an error with its `VerificationStatus`
+var verificationStatusNames = map[VerificationStatus]string{
+	VerificationStatusUnverifiable: "unverifiable",
+	VerificationStatusFalsified:    "falsified",
+	VerificationStatusVerified:     "verified",
+}
+
+func (s VerificationStatus) String() string {
+	if n, ok := verificationStatusNames[s]; ok {
+		return n
+	}
+
+	return fmt.Sprintf("VerificationStatus(%d)", int(s))
+}
+
+// MarshalText implements `encoding.TextMarshaler`
+func (s VerificationStatus) MarshalText() ([]byte, error) {
+	if n, ok := verificationStatusNames[s]; ok {
+		return []byte(n), nil
+	}
+
+	return nil, fmt.Errorf("invalid verification status %d", int(s))
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`
+func (s *VerificationStatus) UnmarshalText(text []byte) error {
+	for k, n := range verificationStatusNames {
+		if n == string(text) {
+			*s = k
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid verification status `%s`", text)
+}
+
+// Code is a stable machine-readable reason of a `VerificationStatusError`
+type Code string
+
+const (
+	// CodeHashMismatch means the hash calculated from the data doesn't match the expected one
+	CodeHashMismatch Code = "HASH_MISMATCH"
+	// CodeAnchorValueMismatch means the value got from an anchor doesn't match the expected one
+	CodeAnchorValueMismatch Code = "ANCHOR_VALUE_MISMATCH"
+	// CodeAnchorUnreachable means the value of an anchor cannot be got
+	CodeAnchorUnreachable Code = "ANCHOR_UNREACHABLE"
+	// CodeAnchorUnsupported means the anchor cannot be verified by this verifier
+	CodeAnchorUnsupported Code = "ANCHOR_UNSUPPORTED"
+	// CodeSignatureInvalid means an embedded signature is invalid
+	CodeSignatureInvalid Code = "SIGNATURE_INVALID"
+	// CodeSchemaInvalid means the Proof is malformed
+	CodeSchemaInvalid Code = "SCHEMA_INVALID"
+	// CodeDocNotFound means the document to be verified cannot be found
+	CodeDocNotFound Code = "DOC_NOT_FOUND"
+	// CodeScopeNotCovered means the Proof doesn't cover the data to be verified
+	CodeScopeNotCovered Code = "SCOPE_NOT_COVERED"
+	// CodeInsufficientConfirmations means an anchor doesn't have enough confirmations yet
+	CodeInsufficientConfirmations Code = "INSUFFICIENT_CONFIRMATIONS"
+	// CodeAnchorUntrusted means an anchoring transaction isn't sent from or to a trusted address
+	CodeAnchorUntrusted Code = "ANCHOR_UNTRUSTED"
+	// CodeAnchorTxFailed means an anchoring transaction has failed, such as being reverted
+	CodeAnchorTxFailed Code = "ANCHOR_TX_FAILED"
+	// CodeAnchorNotCanonical means an anchoring transaction is not in a block of the canonical chain
+	CodeAnchorNotCanonical Code = "ANCHOR_NOT_CANONICAL"
+	// CodeEvidenceMissing means an anchor cannot be verified offline without its evidence
+	CodeEvidenceMissing Code = "EVIDENCE_MISSING"
+	// CodeQuorumNotMet means not enough providers agree on the value of an anchor
+	CodeQuorumNotMet Code = "QUORUM_NOT_MET"
+)
+
+// Sentinel errors to be used with `errors.Is`, which match any `VerificationStatusError` with the
+// same code
+var (
+	ErrHashMismatch              = &VerificationStatusError{Code: CodeHashMismatch}
+	ErrAnchorValueMismatch       = &VerificationStatusError{Code: CodeAnchorValueMismatch}
+	ErrAnchorUnreachable         = &VerificationStatusError{Code: CodeAnchorUnreachable}
+	ErrAnchorUnsupported         = &VerificationStatusError{Code: CodeAnchorUnsupported}
+	ErrSignatureInvalid          = &VerificationStatusError{Code: CodeSignatureInvalid}
+	ErrSchemaInvalid             = &VerificationStatusError{Code: CodeSchemaInvalid}
+	ErrDocNotFound               = &VerificationStatusError{Code: CodeDocNotFound}
+	ErrScopeNotCovered           = &VerificationStatusError{Code: CodeScopeNotCovered}
+	ErrInsufficientConfirmations = &VerificationStatusError{Code: CodeInsufficientConfirmations}
+	ErrAnchorUntrusted           = &VerificationStatusError{Code: CodeAnchorUntrusted}
+	ErrAnchorTxFailed            = &VerificationStatusError{Code: CodeAnchorTxFailed}
+	ErrAnchorNotCanonical        = &VerificationStatusError{Code: CodeAnchorNotCanonical}
+	ErrEvidenceMissing           = &VerificationStatusError{Code: CodeEvidenceMissing}
+	ErrQuorumNotMet              = &VerificationStatusError{Code: CodeQuorumNotMet}
+)
+
+// Location is where in a Proof an error happened
+type Location struct {
+	// Branches are the labels of the branches from the top level branch to the branch where the
+	// error happened
+	Branches []string `json:"branches"`
+	// Op is the index of the op in that branch, or -1 when the error is not about a single op
+	Op int `json:"op"`
+}
+
+func (l *Location) String() string {
+	path := "`" + strings.Join(l.Branches, "/") + "`"
+
+	if l.Op < 0 {
+		return "branch " + path
+	}
+
+	return fmt.Sprintf("op %d of branch %s", l.Op, path)
+}
+
+// VerificationStatusError combines an error with its `VerificationStatus`, and optionally a `Code`
+// and a `Location`
 type VerificationStatusError struct {
-	Status VerificationStatus
-	Err    error
+	Status   VerificationStatus
+	Code     Code
+	Location *Location
+	Err      error
 }
 
 // NewVerificationStatusError creates a new `VerificationStatusError`
@@ -50,6 +166,51 @@ func NewVerificationStatusError(status VerificationStatus, err error) *Verificat
 	}
 }
 
+// NewCodedError creates a new `VerificationStatusError` with a `Code`
+func NewCodedError(status VerificationStatus, code Code, err error) *VerificationStatusError {
+	return &VerificationStatusError{
+		Status: status,
+		Code:   code,
+		Err:    err,
+	}
+}
+
+// At sets the location of the error and returns the error itself
+func (v *VerificationStatusError) At(loc *Location) *VerificationStatusError {
+	v.Location = loc
+	return v
+}
+
 func (v *VerificationStatusError) Error() string {
+	if v.Err == nil {
+		return string(v.Code)
+	}
+
 	return v.Err.Error()
 }
+
+// Unwrap returns the underlying error
+func (v *VerificationStatusError) Unwrap() error {
+	return v.Err
+}
+
+// Is reports whether the target is a sentinel error, such as `ErrHashMismatch`, with the same code
+func (v *VerificationStatusError) Is(target error) bool {
+	t, ok := target.(*VerificationStatusError)
+	if !ok || t.Err != nil || t.Code == "" {
+		return false
+	}
+
+	return t.Code == v.Code
+}
+
+// Of gets the `VerificationStatusError` in the error chain. It returns nil when there is none
+func Of(err error) *VerificationStatusError {
+	var se *VerificationStatusError
+
+	if errors.As(err, &se) {
+		return se
+	}
+
+	return nil
+}
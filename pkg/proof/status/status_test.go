@@ -0,0 +1,137 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T16:02:37+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T16:02:37+11:00
+ */
+
+package status
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestVerificationStatusError(t *testing.T) {
+	cause := errors.New("anchor URI returns 00, but expect ff")
+	loc := &Location{Branches: []string{"pdb_eth_anchor_branch", "eth_anchor_branch"}, Op: 3}
+
+	tests := []struct {
+		name       string
+		err        error
+		target     error
+		wantIs     bool
+		wantStatus VerificationStatus
+		wantLoc    *Location
+	}{
+		{
+			"Match coded error",
+			NewCodedError(VerificationStatusFalsified, CodeAnchorValueMismatch, cause).At(loc),
+			ErrAnchorValueMismatch,
+			true,
+			VerificationStatusFalsified,
+			loc,
+		},
+		{
+			"Match wrapped coded error",
+			fmt.Errorf("failed to verify Proof anchors: %w",
+				NewCodedError(VerificationStatusUnverifiable, CodeAnchorUnreachable, cause)),
+			ErrAnchorUnreachable,
+			true,
+			VerificationStatusUnverifiable,
+			nil,
+		},
+		{
+			"Mismatch code",
+			NewCodedError(VerificationStatusFalsified, CodeHashMismatch, cause),
+			ErrSignatureInvalid,
+			false,
+			VerificationStatusFalsified,
+			nil,
+		},
+		{
+			"Mismatch uncoded error",
+			NewVerificationStatusError(VerificationStatusFalsified, cause),
+			ErrHashMismatch,
+			false,
+			VerificationStatusFalsified,
+			nil,
+		},
+		{
+			"Match cause",
+			NewCodedError(VerificationStatusFalsified, CodeAnchorValueMismatch, cause),
+			cause,
+			true,
+			VerificationStatusFalsified,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.wantIs {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.wantIs)
+			}
+
+			se := Of(tt.err)
+			if se == nil {
+				t.Fatalf("Of() = nil, want a VerificationStatusError")
+			}
+
+			if se.Status != tt.wantStatus || se.Location != tt.wantLoc {
+				t.Errorf("Of() = %#v", se)
+			}
+
+			if se.Error() != cause.Error() {
+				t.Errorf("Error() = %s, want %s", se.Error(), cause)
+			}
+		})
+	}
+
+	if Of(cause) != nil {
+		t.Errorf("Of() = %v, want nil", Of(cause))
+	}
+}
+
+func TestLocationString(t *testing.T) {
+	tests := []struct {
+		name string
+		loc  *Location
+		want string
+	}{
+		{
+			"Op in a nested branch",
+			&Location{Branches: []string{"pdb_btc_anchor_branch", "btc_anchor_branch"}, Op: 15},
+			"op 15 of branch `pdb_btc_anchor_branch/btc_anchor_branch`",
+		},
+		{
+			"Whole branch",
+			&Location{Branches: []string{"btc_anchor_branch"}, Op: -1},
+			"branch `btc_anchor_branch`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loc.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -25,11 +25,13 @@
 package testutil
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
 	"github.com/xeipuuv/gojsonschema"
 )
 
@@ -88,3 +90,39 @@ func LoadString(t *testing.T, name string) string {
 
 	return string(data)
 }
+
+// LoadProof loads given test data into `*model.Proof`
+func LoadProof(t *testing.T, name string) *model.Proof {
+	path := getTestDataPath(t, name)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := model.FromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return proof
+}
+
+// LoadEvaluatedProof loads given test data into `*model.EvaluatedProof`
+func LoadEvaluatedProof(t *testing.T, name string) *model.EvaluatedProof {
+	path := getTestDataPath(t, name)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evaluatedProof := &model.EvaluatedProof{}
+
+	err = json.Unmarshal(data, evaluatedProof)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return evaluatedProof
+}
@@ -0,0 +1,279 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T17:58:21+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T17:58:21+11:00
+ */
+
+// Package server serves the verifications over an HTTP API
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/binary"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMaxBodySize is the default maximum size of a request body in bytes
+	DefaultMaxBodySize = 32 << 20
+	// DefaultTimeout is the default maximum time taken by a verification
+	DefaultTimeout = 2 * time.Minute
+
+	defaultArchiveName = "archive.zip"
+)
+
+// Config represents the config of a Server
+type Config struct {
+	// Verifier verifies the requests. Its event sink should be nil, as the verifications of
+	// different requests run at the same time
+	Verifier *verify.Verifier
+	// Options are the verification options shared by all requests
+	Options verify.Options
+	// Database is the database referenced by the database and document requests. Those requests
+	// are rejected when it is nil
+	Database *mongo.Database
+	// MaxBodySize is the maximum size of a request body in bytes. It defaults to
+	// `DefaultMaxBodySize`
+	MaxBodySize int64
+	// Timeout is the maximum time taken by a verification. It defaults to `DefaultTimeout`
+	Timeout time.Duration
+}
+
+// DatabaseRequest represents a request to verify a database version or a document in it. The
+// latest verifiable version is verified when neither `VersionID` nor `ProofID` is given
+type DatabaseRequest struct {
+	VersionID int64  `json:"versionId,omitempty"`
+	ProofID   string `json:"proofId,omitempty"`
+	// Collection and DocFilter select the document to be verified, which must be both given or left
+	// out. DocFilter is in MongoDB extended JSON format
+	Collection string `json:"collection,omitempty"`
+	DocFilter  string `json:"docFilter,omitempty"`
+}
+
+// ErrorResponse represents the response of a request that cannot be verified
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves the following endpoints, which respond with a `verify.VerificationReport` when the
+// verification has been carried out, or an `ErrorResponse` otherwise:
+//
+//	POST /verify/proof     a Chainpoint Proof in either JSON or base64
+//	POST /verify/archive   a ProvenDB Proof Archive (.zip), optionally named by the `name` query
+//	POST /verify/database  a `DatabaseRequest`
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+}
+
+var _ http.Handler = (*Server)(nil)
+
+// New creates a new Server
+func New(cfg Config) *Server {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultMaxBodySize
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	s := &Server{
+		cfg: cfg,
+		mux: http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/verify/proof", s.post(s.handleProof))
+	s.mux.HandleFunc("/verify/archive", s.post(s.handleArchive))
+	s.mux.HandleFunc("/verify/database", s.post(s.handleDatabase))
+
+	return s
+}
+
+// ServeHTTP implements `http.Handler`
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type handlerFunc func(ctx context.Context, body []byte, r *http.Request) (
+	*verify.VerificationReport, int, error)
+
+// post wraps a handler of POST requests with the body size limit and the verification timeout
+func (s *Server) post(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{"only POST is allowed"})
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxBodySize))
+		if err != nil {
+			var mbErr *http.MaxBytesError
+			if errors.As(err, &mbErr) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{
+					fmt.Sprintf("request body must not be larger than %d bytes", s.cfg.MaxBodySize),
+				})
+				return
+			}
+
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{"cannot read request body: " + err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), s.cfg.Timeout)
+		defer cancel()
+
+		report, code, err := h(ctx, body, r)
+		if err != nil {
+			log.Debugf("%s %s: %s", r.Method, r.URL.Path, err)
+			writeJSON(w, code, ErrorResponse{err.Error()})
+			return
+		}
+
+		log.Debugf("%s %s: %s", r.Method, r.URL.Path, report.Message)
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+func (s *Server) handleProof(ctx context.Context, body []byte, r *http.Request) (
+	*verify.VerificationReport, int, error) {
+	var (
+		proof *model.Proof
+		err   error
+	)
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		proof, err = model.FromJSON(trimmed)
+	} else {
+		var v interface{}
+
+		err = binary.Base642Proof(bytes.NewReader(trimmed), &v)
+		if err == nil {
+			proof, err = model.FromValue(v)
+		}
+	}
+
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("cannot load Chainpoint Proof: %s", err)
+	}
+
+	report, _ := s.cfg.Verifier.Proof(ctx, proof, s.cfg.Options)
+	return report, http.StatusOK, nil
+}
+
+func (s *Server) handleArchive(ctx context.Context, body []byte, r *http.Request) (
+	*verify.VerificationReport, int, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = defaultArchiveName
+	}
+
+	report, _ := s.cfg.Verifier.ArchiveReader(ctx, name, bytes.NewReader(body), int64(len(body)),
+		s.cfg.Options)
+	return report, http.StatusOK, nil
+}
+
+func (s *Server) handleDatabase(ctx context.Context, body []byte, r *http.Request) (
+	*verify.VerificationReport, int, error) {
+	if s.cfg.Database == nil {
+		return nil, http.StatusServiceUnavailable, errors.New("no database is configured")
+	}
+
+	var req DatabaseRequest
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&req)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid request: %s", err)
+	}
+
+	if req.VersionID != 0 && req.ProofID != "" {
+		return nil, http.StatusBadRequest, errors.New("`versionId` and `proofId` cannot be both set")
+	}
+
+	if req.VersionID < 0 {
+		return nil, http.StatusBadRequest, errors.New("`versionId` must be >= 1")
+	}
+
+	if (req.Collection != "") != (req.DocFilter != "") {
+		return nil, http.StatusBadRequest,
+			errors.New("`collection` and `docFilter` must be both specified or left out")
+	}
+
+	var id interface{} = req.VersionID
+
+	if req.ProofID != "" {
+		id = req.ProofID
+	} else if req.VersionID == 0 {
+		v, err := verify.GetLatestVerifiableVersion(ctx, s.cfg.Database)
+		if err != nil {
+			return nil, http.StatusNotFound, fmt.Errorf("failed to get the latest verifiable version: %s", err)
+		}
+
+		id = v
+	}
+
+	vp, err := verify.GetProof(ctx, s.cfg.Database, id, req.Collection)
+	if err != nil {
+		return nil, http.StatusNotFound, fmt.Errorf("cannot get Chainpoint Proof using %v: %s", id, err)
+	}
+
+	var report *verify.VerificationReport
+
+	if req.Collection != "" {
+		report, _ = s.cfg.Verifier.Document(ctx, s.cfg.Database, vp, req.Collection, req.DocFilter,
+			s.cfg.Options)
+	} else {
+		report, _ = s.cfg.Verifier.Database(ctx, s.cfg.Database, vp, s.cfg.Options)
+	}
+
+	return report, http.StatusOK, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		code = http.StatusInternalServerError
+		data, _ = json.Marshal(ErrorResponse{err.Error()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, err = w.Write(append(data, '\n'))
+	if err != nil {
+		log.Debugf("failed to write response: %s", err)
+	}
+}
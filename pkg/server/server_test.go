@@ -0,0 +1,165 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T18:20:47+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T18:20:47+11:00
+ */
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/verify"
+)
+
+func TestServer(t *testing.T) {
+	data, err := ioutil.ReadFile("../proof/testdata/proof1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an invalid hash fails the schema check before any anchor is looked up
+	invalidProof := strings.Replace(string(data),
+		"ffff27222fe366d0b8988b7312c6ba60ee422418d92b62cdcb71fe2991ee7391", "not a hash", 1)
+
+	verifier, err := verify.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(New(Config{
+		Verifier:    verifier,
+		MaxBodySize: int64(len(data)),
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantCode   int
+		wantStatus status.VerificationStatus
+		wantReason status.Code
+	}{
+		{
+			"Invalid JSON Proof",
+			http.MethodPost,
+			"/verify/proof",
+			invalidProof,
+			http.StatusOK,
+			status.VerificationStatusFalsified,
+			status.CodeSchemaInvalid,
+		},
+		{
+			"Malformed base64 Proof",
+			http.MethodPost,
+			"/verify/proof",
+			"not base64",
+			http.StatusBadRequest,
+			0,
+			"",
+		},
+		{
+			"Invalid archive",
+			http.MethodPost,
+			"/verify/archive?name=test.zip",
+			"not a zip",
+			http.StatusOK,
+			status.VerificationStatusFalsified,
+			"",
+		},
+		{
+			"Database not configured",
+			http.MethodPost,
+			"/verify/database",
+			`{"versionId": 1}`,
+			http.StatusServiceUnavailable,
+			0,
+			"",
+		},
+		{
+			"Body too large",
+			http.MethodPost,
+			"/verify/proof",
+			string(data) + " ",
+			http.StatusRequestEntityTooLarge,
+			0,
+			"",
+		},
+		{
+			"Method not allowed",
+			http.MethodGet,
+			"/verify/proof",
+			"",
+			http.StatusMethodNotAllowed,
+			0,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("status code = %v, want %v", resp.StatusCode, tt.wantCode)
+			}
+
+			if tt.wantCode != http.StatusOK {
+				var errResp ErrorResponse
+
+				err = json.NewDecoder(resp.Body).Decode(&errResp)
+				if err != nil || errResp.Error == "" {
+					t.Errorf("error response = %v, %v", errResp, err)
+				}
+				return
+			}
+
+			var report verify.VerificationReport
+
+			err = json.NewDecoder(resp.Body).Decode(&report)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Status != tt.wantStatus || report.Code != tt.wantReason {
+				t.Errorf("report = %s (%s), want %s (%s)", report.Status, report.Code, tt.wantStatus,
+					tt.wantReason)
+			}
+		})
+	}
+}
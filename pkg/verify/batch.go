@@ -0,0 +1,190 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T17:20:31+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T17:20:31+11:00
+ */
+
+package verify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// BatchOptions represents the options of a batch verification
+type BatchOptions struct {
+	Options
+	// Parallelism is the maximum number of versions to be verified at the same time. It defaults
+	// to 1
+	Parallelism int
+}
+
+// BatchReport represents the result of a batch verification
+type BatchReport struct {
+	// Results are the results of the versions in the order they are given
+	Results []*BatchResult `json:"results"`
+	// Verified is the number of verified versions
+	Verified int `json:"verified"`
+	// Falsified is the number of falsified versions
+	Falsified int `json:"falsified"`
+	// Unverifiable is the number of unverifiable versions
+	Unverifiable int `json:"unverifiable"`
+	// StartedAt is the time when the batch verification started
+	StartedAt time.Time `json:"startedAt"`
+	// Duration is the time taken by the batch verification in nanoseconds
+	Duration time.Duration `json:"duration"`
+}
+
+// BatchResult represents the result of verifying a version in a batch
+type BatchResult struct {
+	Version VerifiableVersion         `json:"version"`
+	Status  status.VerificationStatus `json:"status"`
+	// Report is the verification report, which is nil when the stored Proof cannot be loaded
+	Report *VerificationReport `json:"report,omitempty"`
+	// Error is the reason when the version is not verified
+	Error string `json:"error,omitempty"`
+}
+
+// VersionVerified is emitted when a version in a batch is verified
+type VersionVerified struct {
+	Result BatchResult
+}
+
+// EventName implements `event.Event`
+func (VersionVerified) EventName() string {
+	return "version.verified"
+}
+
+// Batch verifies the given versions against their stored Chainpoint Proofs. Anchor lookups are
+// shared by the versions, and `opts.OutPath` is ignored. The events of each version are emitted
+// along with a `VersionVerified` for each version
+func (v *Verifier) Batch(ctx context.Context, database *mongo.Database,
+	versions []VerifiableVersion, opts BatchOptions) *BatchReport {
+	report := &BatchReport{
+		Results:   make([]*BatchResult, len(versions)),
+		StartedAt: time.Now(),
+	}
+
+	bv := *v
+
+	// every version is unverifiable when the anchor config cannot be resolved
+	cfg, cfgErr := v.anchorConfig()
+	if cfgErr == nil {
+		if cfg.Lookups == nil {
+			cfg.Lookups = anchor.NewLookupCache()
+		}
+
+		bv.Anchor = cfg
+	}
+
+	vOpts := opts.Options
+	vOpts.OutPath = ""
+
+	parallelism := opts.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, parallelism)
+	)
+
+	for i := range versions {
+		i := i
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			var r *BatchResult
+
+			if cfgErr != nil {
+				r = &BatchResult{
+					Version: versions[i],
+					Status:  status.VerificationStatusUnverifiable,
+					Error:   cfgErr.Error(),
+				}
+			} else {
+				r = bv.verifyVersion(ctx, database, versions[i], vOpts)
+			}
+
+			report.Results[i] = r
+			event.Emit(v.Events, VersionVerified{*r})
+		}()
+	}
+
+	wg.Wait()
+
+	for _, r := range report.Results {
+		switch r.Status {
+		case status.VerificationStatusVerified:
+			report.Verified++
+		case status.VerificationStatusFalsified:
+			report.Falsified++
+		default:
+			report.Unverifiable++
+		}
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	return report
+}
+
+func (v *Verifier) verifyVersion(ctx context.Context, database *mongo.Database,
+	version VerifiableVersion, opts Options) *BatchResult {
+	r := &BatchResult{
+		Version: version,
+		Status:  status.VerificationStatusUnverifiable,
+	}
+
+	if err := ctx.Err(); err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	vp, err := GetProof(ctx, database, version.ProofID, "")
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	report, err := v.Database(ctx, database, vp, opts)
+	r.Report = report
+	r.Status = report.Status
+
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	return r
+}
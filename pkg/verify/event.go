@@ -0,0 +1,112 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T14:05:12+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T14:05:12+11:00
+ */
+
+package verify
+
+// Stage is a verification stage
+type Stage string
+
+// Verification stages reported by `StageStarted`
+const (
+	// StageLoadArchive loads a ProvenDB Proof Archive. The detail is the archive filename
+	StageLoadArchive Stage = "load_archive"
+	// StageVerifyProof evaluates the Chainpoint Proof and verifies its anchors
+	StageVerifyProof Stage = "verify_proof"
+	// StageVerifySignatures verifies the signatures embedded in the Chainpoint Proof
+	StageVerifySignatures Stage = "verify_signatures"
+	// StageOutputProof outputs the verified Chainpoint Proof. The detail is the output path
+	StageOutputProof Stage = "output_proof"
+)
+
+// StageStarted is emitted when a verification stage starts
+type StageStarted struct {
+	Stage  Stage
+	Detail string
+}
+
+// EventName implements `event.Event`
+func (StageStarted) EventName() string {
+	return "stage.started"
+}
+
+// DatabaseHashStarted is emitted when the collections to be hashed in a database are listed
+type DatabaseHashStarted struct {
+	Database    string
+	Collections int
+}
+
+// EventName implements `event.Event`
+func (DatabaseHashStarted) EventName() string {
+	return "database.hash_started"
+}
+
+// CollectionHashed is emitted when a collection is hashed. `Hash` is nil for an empty collection,
+// which is excluded from the database hash
+type CollectionHashed struct {
+	Database   string
+	Collection string
+	Hash       []byte
+}
+
+// EventName implements `event.Event`
+func (CollectionHashed) EventName() string {
+	return "collection.hashed"
+}
+
+// DatabaseHashFinished is emitted when a database hash, which is started by
+// `DatabaseHashStarted`, is finished or failed
+type DatabaseHashFinished struct {
+	Database string
+	Hash     []byte
+	Err      error
+}
+
+// EventName implements `event.Event`
+func (DatabaseHashFinished) EventName() string {
+	return "database.hash_finished"
+}
+
+// SignatureChecked is emitted when a branch signature is checked
+type SignatureChecked struct {
+	Result SignatureResult
+}
+
+// EventName implements `event.Event`
+func (SignatureChecked) EventName() string {
+	return "signature.checked"
+}
+
+// DocumentMismatch is emitted when the hash of a document doesn't match its expected hash
+type DocumentMismatch struct {
+	DocumentID interface{}
+	Expected   []byte
+	Actual     []byte
+	// Content is the hashed document content in extended JSON when available
+	Content string
+}
+
+// EventName implements `event.Event`
+func (DocumentMismatch) EventName() string {
+	return "document.mismatch"
+}
@@ -22,7 +22,7 @@
  * @Last modified time: 2019-07-09T13:14:44+10:00
  */
 
-package main
+package verify
 
 import (
 	"encoding/hex"
@@ -31,6 +31,7 @@ import (
 	"testing"
 
 	hasher "github.com/SouthbankSoftware/provendb-verify/pkg/crypto/sha256"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
 	"github.com/mongodb/mongo-go-driver/bson"
 	"github.com/mongodb/mongo-go-driver/bson/primitive"
 	"github.com/mongodb/mongo-go-driver/x/bsonx"
@@ -175,8 +176,46 @@ func TestHashDocJSON(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, _, err = hashDocument(doc)
+	_, _, err = (&Verifier{}).hashDocument(doc)
 	if err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestHashDocMismatchEvent(t *testing.T) {
+	doc := bsonx.Doc{}
+	err := bson.UnmarshalExtJSON([]byte(`{
+		"_id": 1,
+		"a": "provenDB",
+		"_provendb_metadata": {
+			"_id": 1,
+			"minVersion": {
+				"$numberLong": "1"
+			},
+			"hash": "0000000000000000000000000000000000000000000000000000000000000000"
+		}
+	}`), true, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []event.Event
+
+	v := &Verifier{
+		Events: event.SinkFunc(func(e event.Event) {
+			events = append(events, e)
+		}),
+	}
+
+	_, _, err = v.hashDocument(doc)
+	assert.Error(t, err)
+
+	if assert.Len(t, events, 1) {
+		e, ok := events[0].(DocumentMismatch)
+		if assert.True(t, ok) {
+			assert.Equal(t, make([]byte, 32), e.Expected)
+			assert.Len(t, e.Actual, 32)
+			assert.Contains(t, e.Content, "provenDB")
+		}
+	}
+}
@@ -22,7 +22,7 @@
  * @Last modified time: 2020-05-19T12:39:40+10:00
  */
 
-package main
+package verify
 
 import (
 	"bytes"
@@ -38,68 +38,69 @@ import (
 	"github.com/SouthbankSoftware/provendb-verify/pkg/merkle"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/binary"
 	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/eval"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
 	"github.com/mongodb/mongo-go-driver/mongo"
 	"github.com/mongodb/mongo-go-driver/mongo/options"
 	"github.com/mongodb/mongo-go-driver/x/bsonx"
 )
 
 var (
-	errProofUnverifiable = errors.New("proof is unverifiable")
+	// ErrProofUnverifiable is returned when a stored Chainpoint Proof is in a status that cannot be
+	// verified
+	ErrProofUnverifiable = errors.New("proof is unverifiable")
 )
 
-func getProofType(proof interface{}) (proofType proofType, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			proofType = ""
-			err = r.(error)
-		}
-	}()
+// VersionProof represents a Chainpoint Proof stored in ProvenDB along with the version it proves
+type VersionProof struct {
+	// ProofID is the ProvenDB Proof ID
+	ProofID string
+	// Status is the ProvenDB Proof status, such as `submitted`, `valid` or `invalid`
+	Status string
+	// Proof is the Chainpoint Proof
+	Proof *model.Proof
+	// Version is the version proved by the Proof
+	Version int64
+	// Collections is the collection scope of the Proof. It is empty when the Proof covers the
+	// whole database
+	Collections []string
+	// Filter is the document filter of a collection level Proof in MongoDB extended JSON format
+	Filter string
+}
 
-	switch label := proof.(map[string]interface{})["branches"].([]interface{})[0].(map[string]interface{})["label"]; label {
+func getProofType(proof *model.Proof) (proofType, error) {
+	if len(proof.Branches) == 0 {
+		return "", errors.New("the Chainpoint Proof doesn't have any branch")
+	}
+
+	switch proof.Branches[0].Label {
 	case provenDBDocBranch:
-		proofType = proofTypes.document
+		return proofTypes.document, nil
 	default:
-		proofType = proofTypes.database
+		return proofTypes.database, nil
 	}
-
-	return
 }
 
-func docProof2DBProof(docProof interface{}) (dbProof interface{}, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			docProof = nil
-			err = r.(error)
-		}
-	}()
-
-	proofMap := docProof.(map[string]interface{})
-	branches := proofMap["branches"].([]interface{})
-	branch := branches[0].(map[string]interface{})
-	if branch["label"] != provenDBDocBranch {
-		err = fmt.Errorf("the input Chainpoint Proof is not a document Proof")
-		return
+func docProof2DBProof(docProof *model.Proof) (*model.Proof, error) {
+	if len(docProof.Branches) == 0 || docProof.Branches[0].Label != provenDBDocBranch {
+		return nil, fmt.Errorf("the input Chainpoint Proof is not a document Proof")
 	}
 
-	startHash, err := hex.DecodeString(proofMap["hash"].(string))
+	startHash, err := hex.DecodeString(docProof.Hash)
 	if err != nil {
-		return
+		return nil, err
 	}
-	_, endHash := eval.Branch(startHash, branch)
-	proofMap["hash"] = hex.EncodeToString(endHash)
-	proofMap["branches"] = branches[1:]
 
-	return proofMap, nil
-}
+	_, endHash := eval.Branch(startHash, &docProof.Branches[0])
 
-func dbProof2DocProof(dbProof interface{}, docMklPrf merkle.Proof) (docProof interface{}, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			docProof = nil
-			err = r.(error)
-		}
-	}()
+	dbProof := *docProof
+	dbProof.Hash = hex.EncodeToString(endHash)
+	dbProof.Branches = docProof.Branches[1:]
+
+	return &dbProof, nil
+}
 
+func dbProof2DocProof(dbProof *model.Proof, docMklPrf merkle.Proof) (*model.Proof, error) {
 	var hash []byte
 
 	switch algo := docMklPrf.ValueHashAlgorithm; algo {
@@ -113,46 +114,41 @@ func dbProof2DocProof(dbProof interface{}, docMklPrf merkle.Proof) (docProof int
 
 	switch algo := docMklPrf.HashCombiningAlgorithm; algo {
 	case merkle.HCAS.Sha256:
-		hca = "sha-256"
+		hca = model.OpSha256
 	default:
 		return nil, fmt.Errorf("%s is not a supported hash combining algorithm", algo)
 	}
 
-	dbProofMap := dbProof.(map[string]interface{})
-	dbProofMap["hash"] = hex.EncodeToString(hash)
-
-	branch := make(map[string]interface{})
-
-	branch["label"] = provenDBDocBranch
-
-	ops := make([]interface{}, 0, len(docMklPrf.Path)*2)
+	branch := model.Branch{
+		Label: provenDBDocBranch,
+		Ops:   make([]model.Op, 0, len(docMklPrf.Path)*2),
+	}
 
 	for _, p := range docMklPrf.Path {
 		if len(p.LeftHash) > 0 {
-			ops = append(ops, map[string]interface{}{
-				"l": hex.EncodeToString(p.LeftHash),
+			branch.Ops = append(branch.Ops, model.Op{
+				L: hex.EncodeToString(p.LeftHash),
 			})
 		} else {
-			ops = append(ops, map[string]interface{}{
-				"r": hex.EncodeToString(p.RightHash),
+			branch.Ops = append(branch.Ops, model.Op{
+				R: hex.EncodeToString(p.RightHash),
 			})
 		}
 
-		ops = append(ops, map[string]interface{}{
-			"op": hca,
+		branch.Ops = append(branch.Ops, model.Op{
+			Op: hca,
 		})
 	}
 
-	branch["ops"] = ops
+	docProof := *dbProof
+	docProof.Hash = hex.EncodeToString(hash)
+	docProof.Branches = append([]model.Branch{branch}, dbProof.Branches...)
 
-	branches := dbProofMap["branches"].([]interface{})
-	branches = append([]interface{}{branch}, branches...)
-	dbProofMap["branches"] = branches
-
-	return dbProof, nil
+	return &docProof, nil
 }
 
-func loadProof(filename string) (proof interface{}, err error) {
+// LoadProof loads a Chainpoint Proof from either a JSON (.json) or a base64 (.txt) file
+func LoadProof(filename string) (proof *model.Proof, err error) {
 	defer func() {
 		if err != nil {
 			proof = nil
@@ -173,21 +169,21 @@ func loadProof(filename string) (proof interface{}, err error) {
 			return
 		}
 
-		err = json.Unmarshal(data, &proof)
-		if err != nil {
-			return
-		}
-	} else {
-		err = binary.Base642Proof(f, &proof)
-		if err != nil {
-			return
-		}
+		return model.FromJSON(data)
 	}
 
-	return
+	var v interface{}
+
+	err = binary.Base642Proof(f, &v)
+	if err != nil {
+		return
+	}
+
+	return model.FromValue(v)
 }
 
-func saveProof(filename string, proof interface{}) (err error) {
+// SaveProof saves a Chainpoint Proof to either a JSON (.json) or a base64 (.txt) file
+func SaveProof(filename string, proof *model.Proof) (err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("cannot save Chainpoint Proof to `%s`: %s", filename, err)
@@ -221,16 +217,11 @@ func saveProof(filename string, proof interface{}) (err error) {
 	return
 }
 
-// getProof gets a Chainpoint Proof and its associated version stored in ProvenDB using either a
-// `proofId` (string) or a `versionId` (int64)
-func getProof(ctx context.Context, database *mongo.Database, id interface{}, colName string) (
-	proof interface{}, version int64, cols []string, filterStr string, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = r.(error)
-		}
-	}()
-
+// GetProof gets a Chainpoint Proof and its associated version stored in ProvenDB using either a
+// `proofId` (string) or a `versionId` (int64). When `colName` is not empty, only a Proof that
+// covers that collection is returned
+func GetProof(ctx context.Context, database *mongo.Database, id interface{}, colName string) (
+	vp VersionProof, err error) {
 	filter := bsonx.Doc{}
 	isByProofID := false
 
@@ -249,7 +240,7 @@ func getProof(ctx context.Context, database *mongo.Database, id interface{}, col
 				})},
 			})})
 	default:
-		err = fmt.Errorf("unsupported ID type %T for getProof", i)
+		err = fmt.Errorf("unsupported ID type %T for GetProof", i)
 		return
 	}
 
@@ -303,6 +294,10 @@ func getProof(ctx context.Context, database *mongo.Database, id interface{}, col
 			}
 
 			err = fmt.Errorf("no proof%s%s can be found", colMsg, statusMsg)
+
+			if colName != "" {
+				err = status.NewCodedError(status.VerificationStatusUnverifiable, status.CodeScopeNotCovered, err)
+			}
 		}
 		return
 	}
@@ -313,32 +308,43 @@ func getProof(ctx context.Context, database *mongo.Database, id interface{}, col
 		return
 	}
 
-	statusMsg := ""
-
-	if isByProofID {
-		if status == "invalid" {
-			statusMsg = ", which is in `invalid` status"
-		} else if status != "submitted" && status != "valid" {
-			err = fmt.Errorf("%w, which is in `%s` status", errProofUnverifiable, status)
-			return
-		}
+	if isByProofID && status != "invalid" && status != "submitted" && status != "valid" {
+		err = fmt.Errorf("%w, which is in `%s` status", ErrProofUnverifiable, status)
+		return
 	}
 
-	fmt.Printf("Loading Chainpoint Proof `%s`%s...\n", doc.Lookup(provenDBProofIDKey), statusMsg)
+	vp.Status = status
+
+	vp.ProofID, ok = doc.Lookup(provenDBProofIDKey).StringValueOK()
+	if !ok {
+		err = fmt.Errorf("cannot get %s", provenDBProofIDKey)
+		return
+	}
 
-	version, ok = doc.Lookup(provenDBVersionKey).Int64OK()
+	vp.Version, ok = doc.Lookup(provenDBVersionKey).Int64OK()
 	if !ok {
 		err = fmt.Errorf("cannot get %s", provenDBVersionKey)
 		return
 	}
 
-	_, proofBytes := doc.Lookup(provenDBProofKey).Binary()
+	_, proofBytes, ok := doc.Lookup(provenDBProofKey).BinaryOK()
+	if !ok {
+		err = fmt.Errorf("cannot get %s", provenDBProofKey)
+		return
+	}
+
+	var proof interface{}
 
 	err = binary.Binary2Proof(bytes.NewBuffer(proofBytes), &proof)
 	if err != nil {
 		return
 	}
 
+	vp.Proof, err = model.FromValue(proof)
+	if err != nil {
+		return
+	}
+
 	scope, ok := doc.Lookup(provenDBScopeKey).StringValueOK()
 	if !ok {
 		err = fmt.Errorf("cannot get %s", provenDBScopeKey)
@@ -346,10 +352,8 @@ func getProof(ctx context.Context, database *mongo.Database, id interface{}, col
 	}
 
 	if scope == provenDBScopeCollection {
-		filterStr, ok = doc.Lookup(provenDBDetailsKey, provenDBFilterKey).StringValueOK()
-		if ok {
-			fmt.Printf("Using proof filter '%s'\n", filterStr)
-		}
+		vp.Filter, _ = doc.Lookup(provenDBDetailsKey, provenDBFilterKey).StringValueOK()
+
 		arr, ok := doc.Lookup(provenDBDetailsKey, provenDBCollectionsKey).ArrayOK()
 		if !ok {
 			err = fmt.Errorf("cannot get %s.%s", provenDBDetailsKey, provenDBCollectionsKey)
@@ -370,7 +374,7 @@ func getProof(ctx context.Context, database *mongo.Database, id interface{}, col
 				return
 			}
 
-			cols = append(cols, name)
+			vp.Collections = append(vp.Collections, name)
 		}
 	}
 
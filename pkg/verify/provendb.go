@@ -22,23 +22,53 @@
  * @Last modified time: 2019-04-02T13:40:57+11:00
  */
 
-package main
+package verify
 
 import (
 	"context"
 	"fmt"
-	"github.com/mongodb/mongo-go-driver/bson/bsontype"
 	"strings"
 	"time"
 
 	"github.com/SouthbankSoftware/provendb-verify/pkg/merkle"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
 	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/bsontype"
 	"github.com/mongodb/mongo-go-driver/mongo"
 	"github.com/mongodb/mongo-go-driver/mongo/options"
 	"github.com/mongodb/mongo-go-driver/x/bsonx"
 )
 
-func getLatestVerifiableVersion(ctx context.Context, database *mongo.Database) (int64, error) {
+const (
+	idKey                         = "_id"
+	mongoDBSystemPrefix           = "system."
+	provenDBMetaPrefix            = "_provendb"
+	provenDBIgnoredSuffix         = "pdbignore"
+	provenDBVersionProofs         = provenDBMetaPrefix + "_versionProofs"
+	provenDBDocMetaKey            = provenDBMetaPrefix + "_metadata"
+	provenDBDocMetaIDKey          = provenDBDocMetaKey + "." + idKey
+	provenDBMinVersionKey         = "minVersion"
+	provenDBForgottenKey          = "forgotten"
+	provenDBDocMetaMinVersionKey  = provenDBDocMetaKey + "." + provenDBMinVersionKey
+	provenDBDocMetaMaxVersionKey  = provenDBDocMetaKey + ".maxVersion"
+	provenDBVersionKey            = "version"
+	provenDBProofIDKey            = "proofId"
+	provenDBSubmittedKey          = "submitted"
+	provenDBStatusKey             = "status"
+	provenDBScopeKey              = "scope"
+	provenDBFilterKey             = "filter"
+	provenDBScopeCollection       = "collection"
+	provenDBDetailsKey            = "details"
+	provenDBCollectionsKey        = "collections"
+	provenDBDetailsCollectionsKey = provenDBDetailsKey + "." + provenDBCollectionsKey
+	provenDBNameKey               = "name"
+	provenDBProofKey              = "proof"
+	provenDBHashKey               = "hash"
+	provenDBDocBranch             = "pdb_doc_branch"
+)
+
+// GetLatestVerifiableVersion gets the latest version that has a Chainpoint Proof stored in ProvenDB
+func GetLatestVerifiableVersion(ctx context.Context, database *mongo.Database) (int64, error) {
 	doc := bsonx.Doc{}
 
 	err := database.
@@ -66,17 +96,88 @@ func getLatestVerifiableVersion(ctx context.Context, database *mongo.Database) (
 	return result, nil
 }
 
-type verifiableVersion struct {
-	proofID         string
-	versionID       int64
-	submitTimestamp time.Time
-	proofStatus     string
+// VerifiableVersion represents a version that has a Chainpoint Proof stored in ProvenDB
+type VerifiableVersion struct {
+	ProofID         string    `json:"proofId"`
+	VersionID       int64     `json:"versionId"`
+	SubmitTimestamp time.Time `json:"submitted"`
+	ProofStatus     string    `json:"status"`
+}
+
+// VersionFilter selects verifiable versions. Its zero value selects all of them
+type VersionFilter struct {
+	// MinVersion is the smallest version to be selected when it is non-zero
+	MinVersion int64
+	// MaxVersion is the largest version to be selected when it is non-zero
+	MaxVersion int64
+	// Since is the earliest Proof submitted time to be selected when it is non-zero
+	Since time.Time
+	// Until is the latest Proof submitted time to be selected when it is non-zero
+	Until time.Time
+	// Statuses are the ProvenDB Proof statuses to be selected, such as `valid`. All statuses are
+	// selected when it is empty
+	Statuses []string
+}
+
+// query converts the filter to a `_provendb_versionProofs` query
+func (f *VersionFilter) query() bsonx.Doc {
+	query := bsonx.Doc{}
+
+	if f.MinVersion != 0 || f.MaxVersion != 0 {
+		cond := bsonx.Doc{}
+
+		if f.MinVersion != 0 {
+			cond = append(cond, bsonx.Elem{"$gte", bsonx.Int64(f.MinVersion)})
+		}
+
+		if f.MaxVersion != 0 {
+			cond = append(cond, bsonx.Elem{"$lte", bsonx.Int64(f.MaxVersion)})
+		}
+
+		query = append(query, bsonx.Elem{provenDBVersionKey, bsonx.Document(cond)})
+	}
+
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		cond := bsonx.Doc{}
+
+		if !f.Since.IsZero() {
+			cond = append(cond, bsonx.Elem{"$gte", bsonx.Time(f.Since)})
+		}
+
+		if !f.Until.IsZero() {
+			cond = append(cond, bsonx.Elem{"$lte", bsonx.Time(f.Until)})
+		}
+
+		query = append(query, bsonx.Elem{provenDBSubmittedKey, bsonx.Document(cond)})
+	}
+
+	if len(f.Statuses) > 0 {
+		vals := bsonx.Arr{}
+
+		for _, s := range f.Statuses {
+			vals = append(vals, bsonx.String(s))
+		}
+
+		query = append(query, bsonx.Elem{provenDBStatusKey, bsonx.Document(bsonx.Doc{
+			{"$in", bsonx.Array(vals)},
+		})})
+	}
+
+	return query
+}
+
+// GetVerifiableVersions gets all the verifiable versions in descending version order
+func GetVerifiableVersions(ctx context.Context, database *mongo.Database) ([]VerifiableVersion, error) {
+	return FindVerifiableVersions(ctx, database, VersionFilter{})
 }
 
-func getVerifiableVersions(ctx context.Context, database *mongo.Database) ([]verifiableVersion, error) {
+// FindVerifiableVersions gets the verifiable versions selected by the filter in descending version
+// order
+func FindVerifiableVersions(ctx context.Context, database *mongo.Database, filter VersionFilter) (
+	[]VerifiableVersion, error) {
 	cur, err := database.
 		Collection(provenDBVersionProofs).
-		Find(ctx, bsonx.Doc{},
+		Find(ctx, filter.query(),
 			options.Find().
 				SetSort(bsonx.Doc{{provenDBVersionKey, bsonx.Int32(-1)}}).
 				SetProjection(bsonx.Doc{
@@ -90,7 +191,7 @@ func getVerifiableVersions(ctx context.Context, database *mongo.Database) ([]ver
 	}
 	defer cur.Close(ctx)
 
-	var result []verifiableVersion
+	var result []VerifiableVersion
 
 	for cur.Next(ctx) {
 		doc := bsonx.Doc{}
@@ -99,28 +200,28 @@ func getVerifiableVersions(ctx context.Context, database *mongo.Database) ([]ver
 			return nil, err
 		}
 
-		var vV verifiableVersion
+		var vV VerifiableVersion
 
 		if p, ok := doc.Lookup(provenDBProofIDKey).StringValueOK(); ok {
-			vV.proofID = p
+			vV.ProofID = p
 		} else {
 			continue
 		}
 
 		if v, ok := doc.Lookup(provenDBVersionKey).Int64OK(); ok {
-			vV.versionID = v
+			vV.VersionID = v
 		} else {
 			continue
 		}
 
 		if t, ok := doc.Lookup(provenDBSubmittedKey).DateTimeOK(); ok {
-			vV.submitTimestamp = time.Unix(0, t*int64(time.Millisecond))
+			vV.SubmitTimestamp = time.Unix(0, t*int64(time.Millisecond))
 		} else {
 			continue
 		}
 
 		if s, ok := doc.Lookup(provenDBStatusKey).StringValueOK(); ok {
-			vV.proofStatus = s
+			vV.ProofStatus = s
 		} else {
 			continue
 		}
@@ -208,11 +309,11 @@ func getIDValueFromHashKey(hashKey []byte) (bsonx.Val, error) {
 	return doc.LookupErr("")
 }
 
-func getDocProofMap(ctx context.Context, database *mongo.Database, version int64, opt *docOpt, proofMap map[string]map[string]*merkle.Proof) (hash []byte, err error) {
+func (v *Verifier) getDocProofMap(ctx context.Context, database *mongo.Database, version int64, opt *docOpt, proofMap map[string]map[string]*merkle.Proof) (hash []byte, err error) {
 	filter := bsonx.Doc{}
 	err = bson.UnmarshalExtJSON([]byte(opt.docFilter), true, &filter)
 	if err != nil {
-		return nil, fmt.Errorf("invalid '--docFilter': %s", err)
+		return nil, fmt.Errorf("invalid document filter: %s", err)
 	}
 
 	var opts []*options.FindOptions
@@ -236,7 +337,7 @@ func getDocProofMap(ctx context.Context, database *mongo.Database, version int64
 
 	for cur.Next(ctx) {
 		if len(docID) > 0 {
-			return nil, fmt.Errorf("please make sure that '--collection' and '--docFilter' combined only returns a single document in version %v", version)
+			return nil, fmt.Errorf("please make sure that the collection and document filter combined only returns a single document in version %v", version)
 		}
 
 		doc := bsonx.Doc{}
@@ -248,17 +349,17 @@ func getDocProofMap(ctx context.Context, database *mongo.Database, version int64
 		var metaDoc bsonx.Doc
 
 		if opt.calcHash {
-			hash, metaDoc, err = hashDocument(doc)
+			hash, metaDoc, err = v.hashDocument(doc)
 			if err != nil {
 				return nil, err
 			}
 		} else {
-			v, err := doc.LookupErr(provenDBDocMetaKey)
+			val, err := doc.LookupErr(provenDBDocMetaKey)
 			if err != nil {
 				return nil, err
 			}
 
-			md, ok := v.DocumentOK()
+			md, ok := val.DocumentOK()
 			if !ok {
 				return nil, fmt.Errorf("%s is not a document", provenDBDocMetaKey)
 			}
@@ -275,7 +376,11 @@ func getDocProofMap(ctx context.Context, database *mongo.Database, version int64
 	}
 
 	if len(docID) == 0 {
-		return nil, fmt.Errorf("'--collection' and '--docFilter' combined doesn't return any document in version %v", version)
+		return nil, status.NewCodedError(
+			status.VerificationStatusUnverifiable,
+			status.CodeDocNotFound,
+			fmt.Errorf("the collection and document filter combined doesn't return any document in version %v", version),
+		)
 	}
 
 	docProofMap, ok := proofMap[opt.colName]
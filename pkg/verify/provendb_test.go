@@ -0,0 +1,79 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T17:34:52+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T17:34:52+11:00
+ */
+
+package verify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongo-go-driver/x/bsonx"
+)
+
+func TestVersionFilterQuery(t *testing.T) {
+	since := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter VersionFilter
+		want   bsonx.Doc
+	}{
+		{
+			"Select all versions",
+			VersionFilter{},
+			bsonx.Doc{},
+		},
+		{
+			"Select a version range",
+			VersionFilter{MinVersion: 3, MaxVersion: 7},
+			bsonx.Doc{
+				{provenDBVersionKey, bsonx.Document(bsonx.Doc{
+					{"$gte", bsonx.Int64(3)},
+					{"$lte", bsonx.Int64(7)},
+				})},
+			},
+		},
+		{
+			"Select a date range and statuses",
+			VersionFilter{Since: since, Until: until, Statuses: []string{"valid", "invalid"}},
+			bsonx.Doc{
+				{provenDBSubmittedKey, bsonx.Document(bsonx.Doc{
+					{"$gte", bsonx.Time(since)},
+					{"$lte", bsonx.Time(until)},
+				})},
+				{provenDBStatusKey, bsonx.Document(bsonx.Doc{
+					{"$in", bsonx.Array(bsonx.Arr{bsonx.String("valid"), bsonx.String("invalid")})},
+				})},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.query(); !got.Equal(tt.want) {
+				t.Errorf("query() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T10:12:31+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T10:12:31+11:00
+ */
+
+package verify
+
+import (
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+// VerificationReport represents the result of a verification, where each stage is recorded
+// separately
+type VerificationReport struct {
+	// Target is the verification target, which is one of `database`, `document`, `raw` and
+	// `archive`
+	Target string `json:"target"`
+	// Name describes the verification target, such as the database name or the document filter
+	Name string `json:"name,omitempty"`
+	// Version is the verified version. It is 0 for a raw Proof or a Proof Archive
+	Version int64 `json:"version,omitempty"`
+	// Status is the overall verification status
+	Status status.VerificationStatus `json:"status"`
+	// Message is the human readable summary of the verification
+	Message string `json:"message"`
+	// Error is the reason when the verification is not verified
+	Error string `json:"error,omitempty"`
+	// Code is the machine-readable reason when the verification is not verified
+	Code status.Code `json:"code,omitempty"`
+	// Location is where in the Chainpoint Proof the verification failed when known
+	Location *status.Location `json:"location,omitempty"`
+	// Schema is the result of the Chainpoint Proof JSON schema check
+	Schema *StageResult `json:"schema,omitempty"`
+	// Hash is the result of the document or database hash comparison
+	Hash *HashResult `json:"hash,omitempty"`
+	// Signatures are the results of all the branch signature checks
+	Signatures []*SignatureResult `json:"signatures,omitempty"`
+	// Anchors are the results of all the anchor checks
+	Anchors []*anchor.Result `json:"anchors,omitempty"`
+	// AnchoredAt is the earliest anchoring time of the verified anchors, by which the data existed.
+	// It is nil when the report is not verified or no verified anchor has a known anchoring time
+	AnchoredAt *time.Time `json:"anchoredAt,omitempty"`
+	// HashSubmittedNodeAt is when the hash was submitted to a Chainpoint Node, as claimed by the
+	// Chainpoint Proof
+	HashSubmittedNodeAt string `json:"hashSubmittedNodeAt,omitempty"`
+	// HashSubmittedCoreAt is when the hash was submitted to a Chainpoint Core, as claimed by the
+	// Chainpoint Proof
+	HashSubmittedCoreAt string `json:"hashSubmittedCoreAt,omitempty"`
+	// StartedAt is the time when the verification started
+	StartedAt time.Time `json:"startedAt"`
+	// Duration is the time taken by the verification in nanoseconds
+	Duration time.Duration `json:"duration"`
+}
+
+// StageResult represents the result of a verification stage
+type StageResult struct {
+	Status   status.VerificationStatus `json:"status"`
+	Error    string                    `json:"error,omitempty"`
+	Code     status.Code               `json:"code,omitempty"`
+	Duration time.Duration             `json:"duration"`
+}
+
+// HashResult represents the result of comparing the hash calculated from the verification target
+// with the hash in the Chainpoint Proof
+type HashResult struct {
+	// Kind is either `document` or `database merkle root`
+	Kind     string                    `json:"kind"`
+	Expected string                    `json:"expected,omitempty"`
+	Actual   string                    `json:"actual,omitempty"`
+	Status   status.VerificationStatus `json:"status"`
+	Error    string                    `json:"error,omitempty"`
+	Code     status.Code               `json:"code,omitempty"`
+	Duration time.Duration             `json:"duration"`
+}
+
+// SignatureResult represents the result of checking the signature embedded in a branch
+type SignatureResult struct {
+	Branch  string                    `json:"branch"`
+	SigHash string                    `json:"sigHash,omitempty"`
+	Status  status.VerificationStatus `json:"status"`
+	Error   string                    `json:"error,omitempty"`
+	Code    status.Code               `json:"code,omitempty"`
+}
+
+func newStageResult(start time.Time, err error) *StageResult {
+	r := &StageResult{
+		Status:   status.VerificationStatusVerified,
+		Duration: time.Since(start),
+	}
+
+	if err != nil {
+		r.Status = statusOf(err, status.VerificationStatusFalsified)
+		r.Error = err.Error()
+		r.Code = codeOf(err)
+	}
+
+	return r
+}
+
+// statusOf gets the `VerificationStatus` carried by the given error, or returns the fallback status
+// when the error doesn't carry one
+func statusOf(err error, fallback status.VerificationStatus) status.VerificationStatus {
+	if err == nil {
+		return status.VerificationStatusVerified
+	}
+
+	if se := status.Of(err); se != nil {
+		return se.Status
+	}
+
+	return fallback
+}
+
+// codeOf gets the `Code` carried by the given error
+func codeOf(err error) status.Code {
+	if se := status.Of(err); se != nil {
+		return se.Code
+	}
+
+	return ""
+}
+
+// setAnchors records the anchor results of the given evaluated Chainpoint Proof along with its
+// anchoring times
+func (r *VerificationReport) setAnchors(p *model.EvaluatedProof, results []*anchor.Result) {
+	r.Anchors = results
+	r.HashSubmittedNodeAt = p.HashSubmittedNodeAt
+	r.HashSubmittedCoreAt = p.HashSubmittedCoreAt
+	r.AnchoredAt = earliestAnchoredAt(results)
+}
+
+// earliestAnchoredAt returns the earliest anchoring time of the verified anchor results
+func earliestAnchoredAt(results []*anchor.Result) *time.Time {
+	var earliest *time.Time
+
+	for _, res := range results {
+		if res.Status != status.VerificationStatusVerified || res.AnchoredAt == nil {
+			continue
+		}
+
+		if earliest == nil || res.AnchoredAt.Before(*earliest) {
+			earliest = res.AnchoredAt
+		}
+	}
+
+	return earliest
+}
+
+// setError records the given error as the reason of the verification, which is then not verified,
+// so the data is not claimed to exist by the anchoring time
+func (r *VerificationReport) setError(err error) {
+	r.Error = err.Error()
+	r.AnchoredAt = nil
+
+	if se := status.Of(err); se != nil {
+		r.Code = se.Code
+		r.Location = se.Location
+	}
+}
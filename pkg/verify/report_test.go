@@ -0,0 +1,101 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-16T23:36:52+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-16T23:36:52+11:00
+ */
+
+package verify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+)
+
+func TestEarliestAnchoredAt(t *testing.T) {
+	early := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		results []*anchor.Result
+		want    *time.Time
+	}{
+		{
+			"No anchoring time",
+			[]*anchor.Result{
+				{Status: status.VerificationStatusVerified},
+			},
+			nil,
+		},
+		{
+			"Earliest verified anchor",
+			[]*anchor.Result{
+				{Status: status.VerificationStatusVerified, AnchoredAt: &late},
+				{Status: status.VerificationStatusVerified},
+				{Status: status.VerificationStatusVerified, AnchoredAt: &early},
+			},
+			&early,
+		},
+		{
+			"Ignore unverified anchor",
+			[]*anchor.Result{
+				{Status: status.VerificationStatusUnverifiable, AnchoredAt: &early},
+				{Status: status.VerificationStatusVerified, AnchoredAt: &late},
+			},
+			&late,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := earliestAnchoredAt(tt.results)
+
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("earliestAnchoredAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerificationReportSetError(t *testing.T) {
+	anchoredAt := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	r := &VerificationReport{}
+	r.setAnchors(&model.EvaluatedProof{}, []*anchor.Result{
+		{Status: status.VerificationStatusVerified, AnchoredAt: &anchoredAt},
+	})
+
+	if r.AnchoredAt == nil {
+		t.Fatal("setAnchors() anchoredAt = nil, want the anchoring time")
+	}
+
+	r.setError(status.NewCodedError(status.VerificationStatusFalsified, status.CodeHashMismatch,
+		errors.New("hash mismatch")))
+
+	if r.AnchoredAt != nil || r.Code != status.CodeHashMismatch {
+		t.Errorf("setError() anchoredAt = %v, code = %s, want no anchoring time and %s", r.AnchoredAt, r.Code,
+			status.CodeHashMismatch)
+	}
+}
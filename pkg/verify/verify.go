@@ -0,0 +1,757 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2019-04-02T13:37:34+11:00
+ * @Last modified by:   Michael Harrison
+ * @Last modified time: 2020-01-15T09:40:28+11:00
+ */
+
+package verify
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/SouthbankSoftware/provendb-verify/pkg/crypto/rsasig"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/merkle"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/anchor"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/eval"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/event"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/model"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/schema"
+	"github.com/SouthbankSoftware/provendb-verify/pkg/proof/status"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/x/bsonx"
+)
+
+// DefaultMaxArchiveSize is the default maximum total uncompressed size of the documents and Proofs
+// read from a ProvenDB Proof Archive
+const DefaultMaxArchiveSize = 64 << 20
+
+type proofType string
+
+var proofTypes = struct {
+	database proofType
+	document proofType
+	raw      proofType
+}{
+	database: "database",
+	document: "document",
+	raw:      "raw",
+}
+
+// Verifier carries the configuration shared by verifications. Verifications with different
+// settings can run at the same time using different Verifiers
+type Verifier struct {
+	// Anchor is the anchor verification config
+	Anchor *anchor.Config
+	// SkipDocCheck indicates whether to skip checking document hash against document metadata
+	SkipDocCheck bool
+	// Events receives the verification events, including the anchor ones when `Anchor.Events` is
+	// nil. No events are emitted when it is nil
+	Events event.Sink
+	// Debug indicates whether to log debug information
+	Debug bool
+	// MaxArchiveSize is the maximum total uncompressed size of the documents and Proofs read from a
+	// ProvenDB Proof Archive. `DefaultMaxArchiveSize` is used when it is zero
+	MaxArchiveSize int64
+}
+
+// NewVerifier creates a Verifier whose defaults are seeded from the environment variables
+func NewVerifier() (*Verifier, error) {
+	cfg, err := anchor.DefaultConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verifier{
+		Anchor: cfg,
+	}, nil
+}
+
+// anchorConfig returns the anchor verification config, which emits to the Verifier's event sink
+// unless it has its own. The defaults seeded from the environment variables are used when the
+// Verifier has no anchor config
+func (v *Verifier) anchorConfig() (*anchor.Config, error) {
+	base := v.Anchor
+	if base == nil {
+		var err error
+
+		base, err = anchor.DefaultConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := *base
+
+	if cfg.Events == nil {
+		cfg.Events = v.Events
+	}
+
+	return &cfg, nil
+}
+
+// Options represents the options of a single verification
+type Options struct {
+	// OutPath is the path to output the Chainpoint Proof when verified. The filename must end
+	// with either `.json` (for JSON) or `.txt` (for compressed binary in base64)
+	OutPath string
+	// PubKey is the RSA public key used to verify the signature contained in a Proof. The
+	// signature is not checked when it is nil
+	PubKey *rsa.PublicKey
+	// IgnoredCollections is the list of collections to be excluded from the database hash
+	IgnoredCollections []string
+}
+
+type docOpt struct {
+	colName   string
+	docFilter string
+	calcHash  bool
+}
+
+// Archive verifies a ProvenDB Proof Archive (.zip), which contains a document (.doc.json) and its
+// Chainpoint Proof (.proof.json)
+func (v *Verifier) Archive(ctx context.Context, filename string, opts Options) (
+	report *VerificationReport, er error) {
+	return v.verifyArchive(ctx, filename, func() (*zip.Reader, func() error, error) {
+		r, err := zip.OpenReader(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &r.Reader, r.Close, nil
+	}, opts)
+}
+
+// ArchiveReader verifies a ProvenDB Proof Archive (.zip) read from the given reader of the given
+// size, such as an uploaded one. The name is used to describe the archive in the report
+func (v *Verifier) ArchiveReader(ctx context.Context, name string, r io.ReaderAt, size int64,
+	opts Options) (report *VerificationReport, er error) {
+	return v.verifyArchive(ctx, name, func() (*zip.Reader, func() error, error) {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return zr, func() error { return nil }, nil
+	}, opts)
+}
+
+// errArchiveTooLarge creates the error of an archive whose entries are larger than the limit
+func errArchiveTooLarge(name, entry string) error {
+	return fmt.Errorf("`%s` in archive `%s` exceeds the maximum archive size", entry, name)
+}
+
+func (v *Verifier) verifyArchive(ctx context.Context, name string,
+	open func() (*zip.Reader, func() error, error), opts Options) (
+	report *VerificationReport, er error) {
+	event.Emit(v.Events, StageStarted{StageLoadArchive, name})
+
+	report = &VerificationReport{
+		Target:    "archive",
+		Name:      name,
+		StartedAt: time.Now(),
+	}
+
+	defer func() {
+		prefix := "ProvenDB Proof Archive"
+
+		report.Status = statusOf(er, status.VerificationStatusFalsified)
+
+		switch report.Status {
+		case status.VerificationStatusVerified:
+			report.Message = prefix + " is verified"
+		case status.VerificationStatusFalsified:
+			report.Message = prefix + " is falsified"
+		default:
+			report.Message = "unable to verify " + prefix
+		}
+
+		if er != nil {
+			report.setError(er)
+		}
+
+		report.Duration = time.Since(report.StartedAt)
+	}()
+
+	r, closeArchive, err := open()
+	if err != nil {
+		er = err
+		return
+	}
+	defer closeArchive()
+
+	var (
+		doc      bsonx.Doc
+		rawProof interface{}
+	)
+
+	remaining := v.MaxArchiveSize
+	if remaining <= 0 {
+		remaining = DefaultMaxArchiveSize
+	}
+
+	getData := func(f *zip.File) (data []byte, err error) {
+		if f.UncompressedSize64 > uint64(remaining) {
+			return nil, errArchiveTooLarge(name, f.Name)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return
+		}
+		defer rc.Close()
+
+		// the declared size can't be trusted, so never read more than the remaining size
+		data, err = ioutil.ReadAll(io.LimitReader(rc, remaining+1))
+		if err != nil {
+			return
+		}
+
+		if int64(len(data)) > remaining {
+			return nil, errArchiveTooLarge(name, f.Name)
+		}
+
+		remaining -= int64(len(data))
+		return
+	}
+
+	for _, f := range r.File {
+		// The __MACOSX folder is created when a Mac user creates and archive (also called a zip
+		// file) using the Mac
+		if f.Mode().IsRegular() && !strings.HasPrefix(f.Name, "__MACOSX") {
+			if strings.HasSuffix(f.Name, ".proof.json") {
+				data, err := getData(f)
+				if err != nil {
+					er = err
+					return
+				}
+
+				err = json.Unmarshal(data, &rawProof)
+				if err != nil {
+					er = err
+					return
+				}
+			} else if strings.HasSuffix(f.Name, ".doc.json") {
+				data, err := getData(f)
+				if err != nil {
+					er = err
+					return
+				}
+
+				err = bson.UnmarshalExtJSON(data, true, &doc)
+				if err != nil {
+					er = err
+					return
+				}
+			}
+		}
+	}
+
+	if len(doc) == 0 {
+		er = fmt.Errorf("`.doc.json` is missing from the archive")
+		return
+	}
+
+	if rawProof == nil {
+		er = fmt.Errorf("`.proof.json` is missing from the archive")
+		return
+	}
+
+	start := time.Now()
+	err = schema.Verify(rawProof)
+	var proof *model.Proof
+	if err == nil {
+		proof, err = model.FromValue(rawProof)
+	}
+	if err == nil {
+		err = proof.Validate()
+	}
+	if err != nil {
+		err = schema.NewInvalidError(err)
+	}
+	report.Schema = newStageResult(start, err)
+	if err != nil {
+		er = err
+		return
+	}
+
+	start = time.Now()
+	report.Hash = &HashResult{
+		Kind: "document",
+	}
+
+	defer func() {
+		if report.Hash != nil && report.Hash.Duration == 0 {
+			report.Hash.Duration = time.Since(start)
+		}
+	}()
+
+	expectedHash, err := hex.DecodeString(proof.Hash)
+	if err != nil {
+		er = schema.NewInvalidError(err)
+		report.Hash.Status = status.VerificationStatusFalsified
+		report.Hash.Error = er.Error()
+		report.Hash.Code = status.CodeSchemaInvalid
+		return
+	}
+
+	report.Hash.Expected = hex.EncodeToString(expectedHash)
+
+	actualHash, _, err := v.hashDocument(doc)
+	if err != nil {
+		report.Hash.Status = statusOf(err, status.VerificationStatusFalsified)
+		report.Hash.Error = err.Error()
+		report.Hash.Code = codeOf(err)
+		er = err
+		return
+	}
+
+	report.Hash.Actual = hex.EncodeToString(actualHash)
+
+	if bytes.Compare(actualHash, expectedHash) != 0 {
+		event.Emit(v.Events, DocumentMismatch{
+			DocumentID: doc.Lookup(idKey).Interface(),
+			Expected:   expectedHash,
+			Actual:     actualHash,
+		})
+
+		er = status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeHashMismatch,
+			fmt.Errorf("document hash mismatched. Expected: %x, actual: %x", expectedHash, actualHash),
+		)
+		report.Hash.Status = status.VerificationStatusFalsified
+		report.Hash.Error = er.Error()
+		report.Hash.Code = status.CodeHashMismatch
+		return
+	}
+
+	report.Hash.Status = status.VerificationStatusVerified
+	report.Hash.Duration = time.Since(start)
+
+	event.Emit(v.Events, StageStarted{Stage: StageVerifyProof})
+
+	evaluatedProof, err := eval.Eval(proof)
+	if err != nil {
+		er = schema.NewInvalidError(err)
+		return
+	}
+
+	if opts.PubKey != nil {
+		_, err := verifyBranchSignatrues(evaluatedProof, opts.PubKey, report, v.Events)
+		if err != nil {
+			er = err
+			return
+		}
+	}
+
+	anchorCfg, err := v.anchorConfig()
+	if err != nil {
+		er = err
+		return
+	}
+
+	results, err := anchor.Verify(ctx, anchorCfg, evaluatedProof)
+	report.setAnchors(evaluatedProof, results)
+	if err != nil {
+		er = err
+		return
+	}
+
+	return
+}
+
+// Proof verifies a Chainpoint Proof itself without checking it against any database or document
+func (v *Verifier) Proof(ctx context.Context, proof *model.Proof, opts Options) (
+	report *VerificationReport, err error) {
+	return v.verifyProof(ctx, nil, VersionProof{Proof: proof}, nil, &opts)
+}
+
+// Database verifies a database version against the given Chainpoint Proof, which can be either a
+// database Proof or a document Proof
+func (v *Verifier) Database(ctx context.Context, database *mongo.Database, vp VersionProof,
+	opts Options) (report *VerificationReport, err error) {
+	return v.verifyProof(ctx, database, vp, nil, &opts)
+}
+
+// Document verifies a document, which is found using the given collection name and document filter
+// in MongoDB extended JSON format, against the given Chainpoint Proof. The Proof can be either a
+// database Proof or a document Proof
+func (v *Verifier) Document(ctx context.Context, database *mongo.Database, vp VersionProof,
+	colName, docFilter string, opts Options) (report *VerificationReport, err error) {
+	return v.verifyProof(ctx, database, vp, &docOpt{
+		colName:   colName,
+		docFilter: docFilter,
+	}, &opts)
+}
+
+func (v *Verifier) verifyProof(
+	ctx context.Context,
+	database *mongo.Database,
+	vp VersionProof,
+	proofDocOpt *docOpt,
+	opts *Options,
+) (report *VerificationReport, err error) {
+	var (
+		inProofType, outProofType proofType
+		proofName                 string
+		proof                     = vp.Proof
+		version                   = vp.Version
+		cols                      = vp.Collections
+	)
+
+	report = &VerificationReport{
+		StartedAt: time.Now(),
+	}
+
+	if database != nil {
+		outProofType = proofTypes.database
+		proofName = "`" + database.Name() + "`"
+		report.Name = database.Name()
+		report.Version = version
+
+		if proofDocOpt != nil {
+			outProofType = proofTypes.document
+			proofName = fmt.Sprintf("in `%s` with filter `%s`", proofDocOpt.colName, proofDocOpt.docFilter)
+			report.Name = fmt.Sprintf("%s.%s %s", database.Name(), proofDocOpt.colName, proofDocOpt.docFilter)
+		}
+	} else {
+		outProofType = proofTypes.raw
+		proofDocOpt = nil
+	}
+
+	report.Target = string(outProofType)
+
+	defer func() {
+		var prefix string
+
+		if outProofType == proofTypes.raw {
+			prefix = "Chainpoint Proof"
+		} else {
+			prefix = fmt.Sprintf("%s%s %s in version %v", strings.ToUpper(string(outProofType[:1])), outProofType[1:], proofName, version)
+		}
+
+		report.Status = statusOf(err, status.VerificationStatusUnverifiable)
+
+		if err != nil {
+			report.setError(err)
+
+			if report.Status == status.VerificationStatusFalsified {
+				report.Message = prefix + " is falsified"
+			} else {
+				report.Message = "unable to verify " + prefix
+			}
+		} else {
+			report.Message = prefix + " is verified"
+		}
+
+		report.Duration = time.Since(report.StartedAt)
+	}()
+
+	if proofDocOpt != nil {
+		if len(cols) > 0 {
+			// has collection scope
+			isInScope := false
+
+			for _, n := range cols {
+				if proofDocOpt.colName == n {
+					isInScope = true
+					break
+				}
+			}
+
+			if !isInScope {
+				err = status.NewCodedError(
+					status.VerificationStatusUnverifiable,
+					status.CodeScopeNotCovered,
+					fmt.Errorf("the collection level version proof doesn't cover the collection `%s`", proofDocOpt.colName),
+				)
+				return
+			}
+		}
+	}
+
+	if proof == nil {
+		err = status.NewVerificationStatusError(
+			status.VerificationStatusUnverifiable,
+			errors.New("the Chainpoint Proof is missing"),
+		)
+		return
+	}
+
+	start := time.Now()
+	err = schema.Verify(proof)
+	if err == nil {
+		err = proof.Validate()
+	}
+	if err != nil {
+		err = schema.NewInvalidError(err)
+	}
+	report.Schema = newStageResult(start, err)
+	if err != nil {
+		return
+	}
+
+	inProofType, err = getProofType(proof)
+	if err != nil {
+		err = schema.NewInvalidError(err)
+		return
+	}
+
+	var hr hashResult
+
+	if database != nil {
+		var (
+			proofMap     map[string]map[string]*merkle.Proof
+			expectedHash []byte
+			actualHash   []byte
+		)
+
+		start := time.Now()
+		report.Hash = &HashResult{
+			Kind: "database merkle root",
+		}
+
+		if outProofType == proofTypes.document {
+			report.Hash.Kind = "document"
+		}
+
+		defer func() {
+			if report.Hash.Duration == 0 {
+				report.Hash.Duration = time.Since(start)
+				report.Hash.Status = statusOf(err, status.VerificationStatusUnverifiable)
+
+				if err != nil {
+					report.Hash.Error = err.Error()
+					report.Hash.Code = codeOf(err)
+				}
+			}
+		}()
+
+		if proofDocOpt != nil {
+			if inProofType == proofTypes.document {
+				proofDocOpt.calcHash = true
+			}
+
+			proofMap = make(map[string]map[string]*merkle.Proof)
+			var hash []byte
+			hash, err = v.getDocProofMap(ctx, database, version, proofDocOpt, proofMap)
+			if err != nil {
+				return
+			}
+
+			if inProofType == proofTypes.document {
+				// we are verifying a document against a document Chainpoint Proof, no need to
+				// reconstruct database merkle tree
+				actualHash = hash
+			}
+		} else if inProofType == proofTypes.document {
+			// we are verifying a database against a document Chainpoint Proof, we need to convert
+			// the Proof to database Proof
+			proof, err = docProof2DBProof(proof)
+			if err != nil {
+				return
+			}
+		}
+
+		expectedHash, err = hex.DecodeString(proof.Hash)
+		if err != nil {
+			err = schema.NewInvalidError(err)
+			return
+		}
+
+		report.Hash.Expected = hex.EncodeToString(expectedHash)
+
+		if actualHash == nil {
+			hr, err = v.hashDatabase(ctx, database, version, proofMap, cols, vp.Filter, opts.IgnoredCollections)
+			if err != nil {
+				return
+			}
+
+			actualHash = hr.hash
+		}
+
+		report.Hash.Actual = hex.EncodeToString(actualHash)
+
+		if bytes.Compare(actualHash, expectedHash) != 0 {
+			err = status.NewCodedError(status.VerificationStatusFalsified, status.CodeHashMismatch, fmt.Errorf("%s hash mismatched. Expected: %x, actual: %x", report.Hash.Kind, expectedHash, actualHash))
+			return
+		}
+
+		report.Hash.Status = status.VerificationStatusVerified
+		report.Hash.Duration = time.Since(start)
+	}
+
+	event.Emit(v.Events, StageStarted{Stage: StageVerifyProof})
+
+	evaluatedProof, err := eval.Eval(proof)
+	if err != nil {
+		err = schema.NewInvalidError(err)
+		return
+	}
+
+	if opts.PubKey != nil {
+		_, err = verifyBranchSignatrues(evaluatedProof, opts.PubKey, report, v.Events)
+		if err != nil {
+			return
+		}
+	}
+
+	anchorCfg, err := v.anchorConfig()
+	if err != nil {
+		return
+	}
+
+	results, err := anchor.Verify(ctx, anchorCfg, evaluatedProof)
+	report.setAnchors(evaluatedProof, results)
+	if err != nil {
+		return
+	}
+
+	if outPath := opts.OutPath; outPath != "" {
+		event.Emit(v.Events, StageStarted{StageOutputProof, outPath})
+
+		if inProofType == proofTypes.database && outProofType == proofTypes.document && len(hr.proofs) > 0 {
+			// convert database Proof to document Proof by embedding document merkle path
+			proof, err = dbProof2DocProof(proof, hr.proofs[0])
+			if err != nil {
+				return
+			}
+		}
+
+		err = SaveProof(outPath, proof)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func verifyBranchSignatrues(evaledPf *model.EvaluatedProof, pub *rsa.PublicKey,
+	report *VerificationReport, events event.Sink) (verifiable bool, er error) {
+	event.Emit(events, StageStarted{Stage: StageVerifySignatures})
+
+	var verify func(parents []string, branches []model.EvaluatedBranch) (
+		verifiable bool, hasSig bool, er error)
+
+	verify = func(parents []string, branches []model.EvaluatedBranch) (
+		verifiable bool, hasSig bool, er error) {
+		for i := range branches {
+			b := &branches[i]
+			labels := append(append([]string{}, parents...), b.Label)
+
+			if b.Sig != "" {
+				sr := &SignatureResult{
+					Branch: b.Label,
+				}
+				report.Signatures = append(report.Signatures, sr)
+
+				v, err := verifyBranchSignature(b, pub)
+				if err != nil {
+					se := status.NewVerificationStatusError(status.VerificationStatusUnverifiable, err)
+					if v {
+						se.Status = status.VerificationStatusFalsified
+						se.Code = status.CodeSignatureInvalid
+					}
+					se.At(&status.Location{Branches: labels, Op: b.SigOp})
+
+					sr.Status = se.Status
+					sr.Error = err.Error()
+					sr.Code = se.Code
+					event.Emit(events, SignatureChecked{*sr})
+
+					verifiable = v
+					er = se
+					return
+				}
+
+				sr.SigHash = b.SigHash
+				sr.Status = status.VerificationStatusVerified
+				event.Emit(events, SignatureChecked{*sr})
+				hasSig = true
+			}
+
+			if b.Branches != nil {
+				v, h, err := verify(labels, b.Branches)
+				if err != nil {
+					verifiable = v
+					er = err
+					return
+				}
+
+				hasSig = hasSig || h
+			}
+		}
+
+		verifiable = true
+		return
+	}
+
+	verifiable, hasSig, er := verify(nil, evaledPf.Branches)
+	if verifiable && er == nil && !hasSig {
+		er = status.NewCodedError(
+			status.VerificationStatusFalsified,
+			status.CodeSignatureInvalid,
+			errors.New("signature is missing"),
+		)
+		return
+	}
+	return
+}
+
+// verifyBranchSignature verifies the signature contained in the evaluated branch `b`
+func verifyBranchSignature(b *model.EvaluatedBranch, pub *rsa.PublicKey) (
+	verifiable bool, er error) {
+	if b.SigHash == "" {
+		return true, fmt.Errorf("`sigHash` is missing in branch `%s`", b.Label)
+	}
+
+	hash, err := hex.DecodeString(b.SigHash)
+	if err != nil {
+		return true, fmt.Errorf("cannot decode `sigHash` in branch `%s`: %w",
+			b.Label, err)
+	}
+
+	vr, err := rsasig.Verify(hash, b.Sig, pub)
+	if err != nil {
+		if vr {
+			return true, fmt.Errorf("falsified signature in branch `%s`: %w",
+				b.Label, err)
+		}
+
+		return false, fmt.Errorf("cannot verify signature in branch `%s`: %w",
+			b.Label, err)
+	}
+
+	return true, nil
+}
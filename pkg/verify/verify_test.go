@@ -0,0 +1,106 @@
+/*
+ * provendb-verify
+ * Copyright (C) 2019  Southbank Software Ltd.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ *
+ * @Author: guiguan
+ * @Date:   2026-10-17T00:04:10+11:00
+ * @Last modified by:   guiguan
+ * @Last modified time: 2026-10-17T00:04:10+11:00
+ */
+
+package verify
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVerifierMaxArchiveSize(t *testing.T) {
+	type entry struct {
+		name string
+		size int
+	}
+
+	tests := []struct {
+		name    string
+		entries []entry
+	}{
+		{
+			"Entry above the limit",
+			[]entry{{"a.doc.json", 2048}},
+		},
+		{
+			"Total above the limit",
+			[]entry{{"a.proof.json", 600}, {"a.doc.json", 600}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			zw := zip.NewWriter(buf)
+
+			for _, e := range tt.entries {
+				w, err := zw.Create(e.name)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// a JSON string of the entry size
+				_, err = w.Write([]byte(`"` + strings.Repeat("0", e.size-2) + `"`))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := zw.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := &Verifier{MaxArchiveSize: 1024}
+
+			_, err = v.ArchiveReader(context.Background(), "test.zip", bytes.NewReader(buf.Bytes()),
+				int64(buf.Len()), Options{})
+			if err == nil || !strings.Contains(err.Error(), "exceeds the maximum archive size") {
+				t.Errorf("ArchiveReader() error = %v, want the archive size exceeded", err)
+			}
+		})
+	}
+}
+
+func TestVerifierBatchWithoutAnchor(t *testing.T) {
+	// a canceled batch does not touch the database
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	v := &Verifier{}
+
+	report := v.Batch(ctx, nil, []VerifiableVersion{{VersionID: 1}, {VersionID: 2}}, BatchOptions{})
+
+	if report.Unverifiable != 2 {
+		t.Fatalf("Batch() unverifiable = %d, want 2", report.Unverifiable)
+	}
+
+	for i, r := range report.Results {
+		if r.Version.VersionID != int64(i+1) || r.Error == "" {
+			t.Errorf("Batch() result %d = %+v, want the version %d unverifiable", i, r, i+1)
+		}
+	}
+}